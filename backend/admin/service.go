@@ -4,7 +4,9 @@ import (
 	"ams/backend/auth"
 	"ams/backend/fabric"
 	"database/sql"
+	"encoding/json"
 	"log"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -32,16 +34,111 @@ func RegisterRoutes(router fiber.Router, db *sql.DB, fab *fabric.Service) {
 	admin.Get("/assets", func(c *fiber.Ctx) error {
 		return getAllAssets(c, db)
 	})
+	admin.Post("/assets/:id/restore", func(c *fiber.Ctx) error {
+		return submitAssetChange(c, fab, "RestoreAsset", "Asset restored", c.Params("id"))
+	})
+	admin.Delete("/assets/:id", func(c *fiber.Ctx) error {
+		var p struct {
+			Reason string `json:"reason"`
+		}
+		if err := c.BodyParser(&p); err != nil || p.Reason == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "reason is required"})
+		}
+		return submitAssetChange(c, fab, "PurgeAsset", "Asset purged", c.Params("id"), p.Reason)
+	})
+
+	// 3b. Legal Freeze (dispute hold on individual assets)
+	admin.Post("/assets/:id/freeze", func(c *fiber.Ctx) error {
+		return submitFreezeChange(c, fab, "FreezeAsset", "Asset frozen")
+	})
+	admin.Post("/assets/:id/unfreeze", func(c *fiber.Ctx) error {
+		return submitFreezeChange(c, fab, "UnfreezeAsset", "Asset unfrozen")
+	})
+	admin.Get("/assets/:id/freezes", func(c *fiber.Ctx) error {
+		return getFreezeHistory(c, fab)
+	})
+	admin.Get("/freezes", func(c *fiber.Ctx) error {
+		return getFreezes(c, db)
+	})
+
+	// 3c. Forced Transfer (court orders and other overrides of the multi-sig flow)
+	admin.Post("/assets/:id/force-transfer", func(c *fiber.Ctx) error {
+		return forceTransfer(c, fab)
+	})
 
 	// 4. Transaction Control
 	admin.Get("/transfers", func(c *fiber.Ctx) error {
 		return getAllPendingTransfers(c, db)
 	})
+	admin.Post("/transfers/:assetId/cancel", func(c *fiber.Ctx) error {
+		return cancelTransfer(c, fab)
+	})
+
+	// 4b. Transfer Approval Policies (M-of-N)
+	admin.Get("/policies", func(c *fiber.Ctx) error {
+		return getTransferPolicies(c, fab)
+	})
+	admin.Put("/policies/:scope/:scopeId", func(c *fiber.Ctx) error {
+		return setTransferPolicy(c, fab)
+	})
+	admin.Delete("/policies/:scope/:scopeId", func(c *fiber.Ctx) error {
+		return deleteTransferPolicy(c, fab)
+	})
+
+	// 4c. Transfer Expiry Windows (per asset type)
+	admin.Get("/expiry-windows/:assetType", func(c *fiber.Ctx) error {
+		return getExpiryWindow(c, fab)
+	})
+	admin.Put("/expiry-windows/:assetType", func(c *fiber.Ctx) error {
+		return setExpiryWindow(c, fab)
+	})
+
+	// 4d. Viewer Groups (granted view access as "group:<name>")
+	admin.Get("/groups", func(c *fiber.Ctx) error {
+		return getGroups(c, fab)
+	})
+	admin.Put("/groups/:groupId", func(c *fiber.Ctx) error {
+		return setGroupMembers(c, fab)
+	})
+	admin.Delete("/groups/:groupId", func(c *fiber.Ctx) error {
+		return submitGroupChange(c, fab, "DeleteGroup", "Group deleted", c.Params("groupId"))
+	})
+	admin.Post("/groups/:groupId/members", func(c *fiber.Ctx) error {
+		var p struct {
+			UserID string `json:"user_id"`
+		}
+		if err := c.BodyParser(&p); err != nil || p.UserID == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "user_id is required"})
+		}
+		return submitGroupChange(c, fab, "AddGroupMember", "Member added", c.Params("groupId"), p.UserID)
+	})
+	admin.Delete("/groups/:groupId/members/:userId", func(c *fiber.Ctx) error {
+		return submitGroupChange(c, fab, "RemoveGroupMember", "Member removed", c.Params("groupId"), c.Params("userId"))
+	})
+
+	// 4e. Token Ledger (balances synced to PostgreSQL, minting on-chain)
+	admin.Get("/tokens/balances", func(c *fiber.Ctx) error {
+		return getTokenBalances(c, db)
+	})
+	admin.Post("/tokens/mint", func(c *fiber.Ctx) error {
+		return mintTokens(c, fab)
+	})
+
+	// 4f. Asset Type Registry (typed custom attributes)
+	admin.Put("/asset-types/:name", func(c *fiber.Ctx) error {
+		return setAssetType(c, fab)
+	})
+	admin.Delete("/asset-types/:name", func(c *fiber.Ctx) error {
+		return submitAssetTypeChange(c, fab, "DeleteAssetType", "Asset type deleted", c.Params("name"))
+	})
 
 	// 5. Network Configuration
 	admin.Get("/health", func(c *fiber.Ctx) error {
 		return getNetworkHealth(c, fab)
 	})
+	admin.Post("/ledger/migrate-keys", func(c *fiber.Ctx) error {
+		return migrateLegacyKeys(c, fab)
+	})
 }
 // Middleware to ensure user has Admin role
 func requireAdminRole(c *fiber.Ctx) error {
@@ -90,9 +187,46 @@ func getDashboardStats(c *fiber.Ctx, db *sql.DB) error {
 	}
 	stats["pending_transfers"] = pendingCount
 
+	// 4. Forced Transfers: overrides of the multi-sig flow are surfaced for review
+	var forcedCount int
+	if err := db.QueryRow("SELECT COUNT(*) FROM asset_history WHERE action_type = 'FORCE_TRANSFER'").Scan(&forcedCount); err != nil {
+		log.Printf("Warning: failed to count forced transfers: %v", err)
+	}
+	stats["force_transfers"] = forcedCount
+	stats["recent_force_transfers"] = getRecentForceTransfers(db)
+
 	return c.JSON(stats)
 }
 
+// getRecentForceTransfers returns the latest forced transfers with their reason and document hash
+func getRecentForceTransfers(db *sql.DB) []map[string]interface{} {
+	forced := []map[string]interface{}{}
+	rows, err := db.Query(`
+		SELECT tx_id, asset_id, COALESCE(from_owner, ''), COALESCE(to_owner, ''), COALESCE(actor_id, ''),
+			COALESCE(details->>'reason', ''), COALESCE(details->>'documentHash', ''), timestamp
+		FROM asset_history
+		WHERE action_type = 'FORCE_TRANSFER'
+		ORDER BY timestamp DESC, id DESC LIMIT 5`)
+	if err != nil {
+		log.Printf("Warning: failed to fetch forced transfers: %v", err)
+		return forced
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var txID, assetID, fromOwner, toOwner, actorID, reason, documentHash string
+		var timestamp time.Time
+		if err := rows.Scan(&txID, &assetID, &fromOwner, &toOwner, &actorID, &reason, &documentHash, &timestamp); err != nil {
+			continue
+		}
+		forced = append(forced, map[string]interface{}{
+			"tx_id": txID, "asset_id": assetID, "from_owner": fromOwner, "to_owner": toOwner,
+			"actor_id": actorID, "reason": reason, "document_hash": documentHash, "timestamp": timestamp,
+		})
+	}
+	return forced
+}
+
 func getAllUsers(c *fiber.Ctx, db *sql.DB) error {
 	query := `
 		SELECT id, full_name, role, identity_number, wallet_address, status,
@@ -161,9 +295,12 @@ func setUserStatus(c *fiber.Ctx, fab *fabric.Service) error {
 	}
 
 	// Submit Transaction
-	_, err = contract.SubmitTransaction("SetUserStatus", targetUserID, p.Status, claims.UserID)
+	_, err = contract.SubmitTransaction("SetUserStatus", targetUserID, p.Status)
 	if err != nil {
 		log.Printf("❌ Failed to set user status: %v", err)
+		if fabric.IsUserLocked(err) {
+			return c.Status(403).JSON(fiber.Map{"error": "Account is Locked: " + fabric.ErrorDetails(err), "code": "USER_LOCKED"})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "Blockchain transaction failed: " + err.Error()})
 	}
 
@@ -174,9 +311,485 @@ func setUserStatus(c *fiber.Ctx, fab *fabric.Service) error {
 	})
 }
 
+// Cancel a stuck pending transfer (admin override, reason is recorded on-chain)
+func cancelTransfer(c *fiber.Ctx, fab *fabric.Service) error {
+	assetID := c.Params("assetId")
+
+	type CancelRequest struct {
+		Reason string `json:"reason"`
+	}
+	p := new(CancelRequest)
+	if err := c.BodyParser(p); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	if p.Reason == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Reason is required"})
+	}
+
+	claims := c.Locals("user").(*auth.Claims)
+
+	log.Printf("🚫 Admin %s cancelling transfer of %s. Reason: %s", claims.UserID, assetID, p.Reason)
+
+	contract, err := fab.GetContractForUser(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get contract: " + err.Error()})
+	}
+
+	_, err = contract.SubmitTransaction("AdminCancelTransfer", assetID, p.Reason)
+	if err != nil {
+		log.Printf("❌ Failed to cancel transfer: %v", err)
+		if fabric.IsUserLocked(err) {
+			return c.Status(403).JSON(fiber.Map{"error": "Account is Locked: " + fabric.ErrorDetails(err), "code": "USER_LOCKED"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Blockchain transaction failed: " + fabric.ErrorDetails(err)})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Transfer cancelled successfully",
+		"asset_id": assetID,
+		"status": "CANCELLED",
+		"cancelled_by": claims.UserID,
+	})
+}
+
+func getTransferPolicies(c *fiber.Ctx, fab *fabric.Service) error {
+	claims := c.Locals("user").(*auth.Claims)
+	contract, err := fab.GetContractForUser(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get contract: " + err.Error()})
+	}
+
+	result, err := contract.EvaluateTransaction("GetAllTransferPolicies")
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch transfer policies: " + fabric.ErrorDetails(err)})
+	}
+
+	c.Set("Content-Type", "application/json")
+	return c.Send(result)
+}
+
+// Set Transfer Policy for an asset type ("type") or a single asset ("asset")
+func setTransferPolicy(c *fiber.Ctx, fab *fabric.Service) error {
+	scope := c.Params("scope")
+	scopeID := c.Params("scopeId")
+
+	type PolicyRequest struct {
+		Threshold     int      `json:"threshold"`
+		ApproverRoles []string `json:"approverRoles"`
+		ApproverUsers []string `json:"approverUsers"`
+	}
+	p := new(PolicyRequest)
+	if err := c.BodyParser(p); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	if scope != "type" && scope != "asset" {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid scope. Must be 'type' or 'asset'"})
+	}
+	if p.ApproverRoles == nil {
+		p.ApproverRoles = []string{}
+	}
+	if p.ApproverUsers == nil {
+		p.ApproverUsers = []string{}
+	}
+
+	claims := c.Locals("user").(*auth.Claims)
+	log.Printf("📜 Admin %s setting %d-of-N transfer policy for %s %s", claims.UserID, p.Threshold, scope, scopeID)
+
+	contract, err := fab.GetContractForUser(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get contract: " + err.Error()})
+	}
+
+	rolesJSON, _ := json.Marshal(p.ApproverRoles)
+	usersJSON, _ := json.Marshal(p.ApproverUsers)
+	_, err = contract.SubmitTransaction("SetTransferPolicy", scope, scopeID, strconv.Itoa(p.Threshold), string(rolesJSON), string(usersJSON))
+	if err != nil {
+		log.Printf("❌ Failed to set transfer policy: %v", err)
+		if fabric.IsUserLocked(err) {
+			return c.Status(403).JSON(fiber.Map{"error": "Account is Locked: " + fabric.ErrorDetails(err), "code": "USER_LOCKED"})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": "Failed to set transfer policy: " + fabric.ErrorDetails(err)})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Transfer policy saved",
+		"scope": scope,
+		"scopeId": scopeID,
+		"threshold": p.Threshold,
+	})
+}
+
+func deleteTransferPolicy(c *fiber.Ctx, fab *fabric.Service) error {
+	scope := c.Params("scope")
+	scopeID := c.Params("scopeId")
+	claims := c.Locals("user").(*auth.Claims)
+
+	contract, err := fab.GetContractForUser(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get contract: " + err.Error()})
+	}
+
+	_, err = contract.SubmitTransaction("DeleteTransferPolicy", scope, scopeID)
+	if err != nil {
+		log.Printf("❌ Failed to delete transfer policy: %v", err)
+		if fabric.IsUserLocked(err) {
+			return c.Status(403).JSON(fiber.Map{"error": "Account is Locked: " + fabric.ErrorDetails(err), "code": "USER_LOCKED"})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": "Failed to delete transfer policy: " + fabric.ErrorDetails(err)})
+	}
+
+	return c.JSON(fiber.Map{"message": "Transfer policy deleted", "scope": scope, "scopeId": scopeID})
+}
+
+func getGroups(c *fiber.Ctx, fab *fabric.Service) error {
+	claims := c.Locals("user").(*auth.Claims)
+	contract, err := fab.GetContractForUser(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get contract: " + err.Error()})
+	}
+
+	result, err := contract.EvaluateTransaction("GetAllGroups")
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch groups: " + fabric.ErrorDetails(err)})
+	}
+
+	c.Set("Content-Type", "application/json")
+	return c.Send(result)
+}
+
+// Create a viewer group or replace its members
+func setGroupMembers(c *fiber.Ctx, fab *fabric.Service) error {
+	var p struct {
+		Members []string `json:"members"`
+	}
+	if err := c.BodyParser(&p); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	if p.Members == nil {
+		p.Members = []string{}
+	}
+	membersJSON, _ := json.Marshal(p.Members)
+	return submitGroupChange(c, fab, "SetGroupMembers", "Group saved", c.Params("groupId"), string(membersJSON))
+}
+
+// submitGroupChange submits one of the admin group transactions as the calling admin
+func submitGroupChange(c *fiber.Ctx, fab *fabric.Service, txName string, message string, args ...string) error {
+	claims := c.Locals("user").(*auth.Claims)
+	log.Printf("👥 Admin %s: %s %v", claims.UserID, txName, args)
+
+	contract, err := fab.GetContractForUser(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get contract: " + err.Error()})
+	}
+
+	_, err = contract.SubmitTransaction(txName, args...)
+	if err != nil {
+		log.Printf("❌ %s failed: %v", txName, err)
+		if fabric.IsUserLocked(err) {
+			return c.Status(403).JSON(fiber.Map{"error": "Account is Locked: " + fabric.ErrorDetails(err), "code": "USER_LOCKED"})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": message + " failed: " + fabric.ErrorDetails(err)})
+	}
+
+	return c.JSON(fiber.Map{"message": message, "groupId": c.Params("groupId")})
+}
+
+// submitAssetChange submits one of the admin asset transactions (restore, purge, freeze, unfreeze) as the calling admin
+func submitAssetChange(c *fiber.Ctx, fab *fabric.Service, txName string, message string, args ...string) error {
+	claims := c.Locals("user").(*auth.Claims)
+	log.Printf("🗄️ Admin %s: %s %v", claims.UserID, txName, args)
+
+	contract, err := fab.GetContractForUser(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get contract: " + err.Error()})
+	}
+
+	if _, err := contract.SubmitTransaction(txName, args...); err != nil {
+		log.Printf("❌ %s failed: %v", txName, err)
+		if fabric.IsUserLocked(err) {
+			return c.Status(403).JSON(fiber.Map{"error": "Account is Locked: " + fabric.ErrorDetails(err), "code": "USER_LOCKED"})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": message + " failed: " + fabric.ErrorDetails(err)})
+	}
+
+	return c.JSON(fiber.Map{"message": message, "asset_id": c.Params("id")})
+}
+
+// Register an asset type or replace its attribute definitions
+func setAssetType(c *fiber.Ctx, fab *fabric.Service) error {
+	var p struct {
+		Description          string            `json:"description"`
+		Attributes           []json.RawMessage `json:"attributes"`
+		RequiredAttestations []string          `json:"required_attestations"` // Attestation types needed before a transfer executes
+	}
+	if err := c.BodyParser(&p); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	if p.Attributes == nil {
+		p.Attributes = []json.RawMessage{}
+	}
+	definitionJSON, _ := json.Marshal(map[string]interface{}{
+		"description":          p.Description,
+		"attributes":           p.Attributes,
+		"requiredAttestations": p.RequiredAttestations,
+	})
+	return submitAssetTypeChange(c, fab, "SetAssetType", "Asset type saved", c.Params("name"), string(definitionJSON))
+}
+
+// submitAssetTypeChange submits one of the admin asset type transactions as the calling admin
+func submitAssetTypeChange(c *fiber.Ctx, fab *fabric.Service, txName string, message string, args ...string) error {
+	claims := c.Locals("user").(*auth.Claims)
+	log.Printf("🏷️ Admin %s: %s %v", claims.UserID, txName, args)
+
+	contract, err := fab.GetContractForUser(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get contract: " + err.Error()})
+	}
+
+	if _, err := contract.SubmitTransaction(txName, args...); err != nil {
+		log.Printf("❌ %s failed: %v", txName, err)
+		if fabric.IsUserLocked(err) {
+			return c.Status(403).JSON(fiber.Map{"error": "Account is Locked: " + fabric.ErrorDetails(err), "code": "USER_LOCKED"})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": message + " failed: " + fabric.ErrorDetails(err)})
+	}
+
+	return c.JSON(fiber.Map{"message": message, "name": c.Params("name")})
+}
+
+// submitFreezeChange freezes or unfreezes an asset as the calling admin; both need a case reference and a reason
+func submitFreezeChange(c *fiber.Ctx, fab *fabric.Service, txName string, message string) error {
+	var p struct {
+		CaseRef string `json:"case_ref"`
+		Reason  string `json:"reason"`
+	}
+	if err := c.BodyParser(&p); err != nil || p.CaseRef == "" || p.Reason == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "case_ref and reason are required"})
+	}
+	return submitAssetChange(c, fab, txName, message, c.Params("id"), p.CaseRef, p.Reason)
+}
+
+// forceTransfer moves an asset to a new owner as the calling admin, bypassing the multi-sig flow.
+// A reason and the SHA-256 of the authorising document (e.g. a court order) are required.
+func forceTransfer(c *fiber.Ctx, fab *fabric.Service) error {
+	var p struct {
+		NewOwner     string `json:"new_owner"`
+		Reason       string `json:"reason"`
+		DocumentHash string `json:"document_hash"`
+	}
+	if err := c.BodyParser(&p); err != nil || p.NewOwner == "" || p.Reason == "" || p.DocumentHash == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "new_owner, reason and document_hash are required"})
+	}
+	return submitAssetChange(c, fab, "AdminForceTransfer", "Asset force-transferred", c.Params("id"), p.NewOwner, p.Reason, p.DocumentHash)
+}
+
+// Freeze history of one asset, read from the ledger
+func getFreezeHistory(c *fiber.Ctx, fab *fabric.Service) error {
+	claims := c.Locals("user").(*auth.Claims)
+	contract, err := fab.GetContractForUser(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get contract: " + err.Error()})
+	}
+
+	result, err := contract.EvaluateTransaction("GetFreezeHistory", c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Failed to fetch freeze history: " + fabric.ErrorDetails(err)})
+	}
+
+	c.Set("Content-Type", "application/json")
+	return c.Send(result)
+}
+
+// Freezes and unfreezes across assets (PostgreSQL). ?case_ref= narrows to one case, ?active=true to assets still frozen.
+func getFreezes(c *fiber.Ctx, db *sql.DB) error {
+	if db == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "Database not available"})
+	}
+	q := `
+		SELECT f.tx_id, f.asset_id, f.action, f.case_ref, COALESCE(f.reason, ''), COALESCE(f.actor_id, ''), f.timestamp
+		FROM asset_freezes f`
+	args := []interface{}{}
+	if c.QueryBool("active") {
+		q += " JOIN assets a ON a.id = f.asset_id AND a.frozen_case = f.case_ref WHERE f.action = 'FREEZE'"
+	} else {
+		q += " WHERE 1=1"
+	}
+	if caseRef := c.Query("case_ref"); caseRef != "" {
+		args = append(args, caseRef)
+		q += " AND f.case_ref = $1"
+	}
+	q += " ORDER BY f.timestamp DESC, f.id DESC LIMIT 200"
+
+	rows, err := db.Query(q, args...)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch freezes: " + err.Error()})
+	}
+	defer rows.Close()
+
+	freezes := []map[string]interface{}{}
+	for rows.Next() {
+		var txID, assetID, action, caseRef, reason, actorID string
+		var timestamp time.Time
+		if err := rows.Scan(&txID, &assetID, &action, &caseRef, &reason, &actorID, &timestamp); err != nil {
+			continue
+		}
+		freezes = append(freezes, map[string]interface{}{
+			"tx_id": txID, "asset_id": assetID, "action": action, "case_ref": caseRef,
+			"reason": reason, "actor_id": actorID, "timestamp": timestamp,
+		})
+	}
+	return c.JSON(freezes)
+}
+
+func getTokenBalances(c *fiber.Ctx, db *sql.DB) error {
+	if db == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "Database not available"})
+	}
+	rows, err := db.Query(`
+		SELECT account, balance, updated_at
+		FROM token_balances
+		ORDER BY balance DESC, account
+	`)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch balances: " + err.Error()})
+	}
+	defer rows.Close()
+
+	balances := []map[string]interface{}{}
+	for rows.Next() {
+		var account string
+		var balance int64
+		var updatedAt time.Time
+		if err := rows.Scan(&account, &balance, &updatedAt); err != nil {
+			continue
+		}
+		balances = append(balances, map[string]interface{}{"account": account, "balance": balance, "updated_at": updatedAt})
+	}
+	return c.JSON(balances)
+}
+
+// Mint new tokens into a user's account
+func mintTokens(c *fiber.Ctx, fab *fabric.Service) error {
+	var p struct {
+		Account string `json:"account"`
+		Amount  int64  `json:"amount"`
+	}
+	if err := c.BodyParser(&p); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	if p.Account == "" || p.Amount <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "account and a positive amount are required"})
+	}
+
+	claims := c.Locals("user").(*auth.Claims)
+	log.Printf("🪙 Admin %s minting %d tokens for %s", claims.UserID, p.Amount, p.Account)
+
+	contract, err := fab.GetContractForUser(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get contract: " + err.Error()})
+	}
+
+	result, err := contract.SubmitTransaction("MintTokens", p.Account, strconv.FormatInt(p.Amount, 10))
+	if err != nil {
+		log.Printf("❌ Failed to mint tokens: %v", err)
+		if fabric.IsUserLocked(err) {
+			return c.Status(403).JSON(fiber.Map{"error": "Account is Locked: " + fabric.ErrorDetails(err), "code": "USER_LOCKED"})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": "Failed to mint tokens: " + fabric.ErrorDetails(err)})
+	}
+
+	c.Set("Content-Type", "application/json")
+	return c.Send(result)
+}
+
+// Move one batch of pre composite-key records to their namespaced keys.
+// Repeat with the returned bookmark until it comes back empty.
+func migrateLegacyKeys(c *fiber.Ctx, fab *fabric.Service) error {
+	var p struct {
+		BatchSize int    `json:"batch_size"` // Optional, 0 = chaincode maximum
+		Bookmark  string `json:"bookmark"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&p); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+		}
+	}
+
+	claims := c.Locals("user").(*auth.Claims)
+	log.Printf("🔑 Admin %s migrating legacy keys from %q", claims.UserID, p.Bookmark)
+
+	contract, err := fab.GetContractForUser(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get contract: " + err.Error()})
+	}
+
+	result, err := contract.SubmitTransaction("MigrateLegacyKeys", strconv.Itoa(p.BatchSize), p.Bookmark)
+	if err != nil {
+		log.Printf("❌ Key migration failed: %v", err)
+		if fabric.IsUserLocked(err) {
+			return c.Status(403).JSON(fiber.Map{"error": "Account is Locked: " + fabric.ErrorDetails(err), "code": "USER_LOCKED"})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": "Key migration failed: " + fabric.ErrorDetails(err)})
+	}
+
+	c.Set("Content-Type", "application/json")
+	return c.Send(result)
+}
+
+func getExpiryWindow(c *fiber.Ctx, fab *fabric.Service) error {
+	claims := c.Locals("user").(*auth.Claims)
+	contract, err := fab.GetContractForUser(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get contract: " + err.Error()})
+	}
+
+	result, err := contract.EvaluateTransaction("GetExpiryWindow", c.Params("assetType"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch expiry window: " + fabric.ErrorDetails(err)})
+	}
+
+	c.Set("Content-Type", "application/json")
+	return c.Send(result)
+}
+
+// Set the min/max/default transfer approval window (in hours) for an asset type
+func setExpiryWindow(c *fiber.Ctx, fab *fabric.Service) error {
+	assetType := c.Params("assetType")
+
+	type WindowRequest struct {
+		MinHours     float64 `json:"min_hours"`
+		MaxHours     float64 `json:"max_hours"`
+		DefaultHours float64 `json:"default_hours"`
+	}
+	p := new(WindowRequest)
+	if err := c.BodyParser(p); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	claims := c.Locals("user").(*auth.Claims)
+	log.Printf("⏱️ Admin %s setting expiry window for %s: %.1fh-%.1fh (default %.1fh)", claims.UserID, assetType, p.MinHours, p.MaxHours, p.DefaultHours)
+
+	contract, err := fab.GetContractForUser(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get contract: " + err.Error()})
+	}
+
+	toSeconds := func(hours float64) string { return strconv.FormatInt(int64(hours*3600), 10) }
+	_, err = contract.SubmitTransaction("SetExpiryWindow", assetType, toSeconds(p.MinHours), toSeconds(p.MaxHours), toSeconds(p.DefaultHours))
+	if err != nil {
+		log.Printf("❌ Failed to set expiry window: %v", err)
+		if fabric.IsUserLocked(err) {
+			return c.Status(403).JSON(fiber.Map{"error": "Account is Locked: " + fabric.ErrorDetails(err), "code": "USER_LOCKED"})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": "Failed to set expiry window: " + fabric.ErrorDetails(err)})
+	}
+
+	return c.JSON(fiber.Map{"message": "Expiry window saved", "asset_type": assetType})
+}
+
 func getAllAssets(c *fiber.Ctx, db *sql.DB) error {
 	rows, err := db.Query(`
-		SELECT id, name, asset_type, owner, status, updated_at
+		SELECT id, name, asset_type, owner, status, updated_at, COALESCE(archived_by, ''), COALESCE(archive_reason, ''), COALESCE(frozen_case, '')
 		FROM assets
 		ORDER BY updated_at DESC
 	`)
@@ -187,13 +800,14 @@ func getAllAssets(c *fiber.Ctx, db *sql.DB) error {
 
 	var assets []map[string]interface{}
 	for rows.Next() {
-		var id, name, assetType, owner, status string
+		var id, name, assetType, owner, status, archivedBy, archiveReason, frozenCase string
 		var updatedAt time.Time
-		if err := rows.Scan(&id, &name, &assetType, &owner, &status, &updatedAt); err != nil {
+		if err := rows.Scan(&id, &name, &assetType, &owner, &status, &updatedAt, &archivedBy, &archiveReason, &frozenCase); err != nil {
 			continue
 		}
 		assets = append(assets, map[string]interface{}{
 			"id": id, "name": name, "type": assetType, "owner": owner, "status": status, "updated_at": updatedAt,
+			"archived_by": archivedBy, "archive_reason": archiveReason, "frozen_case": frozenCase,
 		})
 	}
 	if assets == nil { assets = []map[string]interface{}{} }
@@ -25,8 +25,10 @@ func NewCAClient() *CAClient {
 	}
 }
 
-// RegisterAndEnroll registers a new user and enrolls them to generate crypto material
-func (c *CAClient) RegisterAndEnroll(username, password string) error {
+// RegisterAndEnroll registers a new user and enrolls them to generate crypto material.
+// The role is issued as the "role" attribute of the enrollment certificate so the
+// chaincode can enforce it on-chain.
+func (c *CAClient) RegisterAndEnroll(username, password, role string) error {
 	log.Printf("🔹 Starting CA Registration for %s...", username)
 
 	clientHome := "/tmp/fabric-ca-client"
@@ -48,12 +50,13 @@ func (c *CAClient) RegisterAndEnroll(username, password string) error {
 	}
 
 	// 2. Register User
-	// We register the user as type 'client'
-	log.Println("🔹 Registering User...")
+	// We register the user as type 'client' with the role attribute added to the ecert by default
+	log.Printf("🔹 Registering User with role %s...", role)
 	cmdRegister := exec.Command("fabric-ca-client", "register",
 		"--id.name", username,
 		"--id.secret", password,
 		"--id.type", "client",
+		"--id.attrs", fmt.Sprintf("role=%s:ecert", role),
 		"--tls.certfiles", c.CaTlsCert,
 		"--home", clientHome,
 		"--mspdir", "msp", // Admin's MSP
@@ -64,6 +67,7 @@ func (c *CAClient) RegisterAndEnroll(username, password string) error {
 		outStr := string(output)
 		// Check if already registered
 		if strings.Contains(outStr, "already registered") {
+			// Existing identities keep their registered attributes; the certificate role is not changed here
 			log.Printf("User %s already registered, proceeding to enroll", username)
 		} else {
 			return fmt.Errorf("failed to register user: %v, output: %s", err, outStr)
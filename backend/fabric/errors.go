@@ -0,0 +1,41 @@
+package fabric
+
+import (
+	"strings"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"google.golang.org/grpc/status"
+)
+
+// userLockedCode is the prefix the chaincode puts on errors caused by a locked user
+const userLockedCode = "USER_LOCKED"
+
+// accessDeniedCode is the prefix the chaincode puts on errors caused by a caller lacking permission
+const accessDeniedCode = "access denied"
+
+// ErrorDetails flattens a gateway error and the per-peer chaincode messages attached to it.
+// The top-level gRPC message alone ("failed to endorse transaction, see attached details")
+// does not contain the chaincode error.
+func ErrorDetails(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	messages := []string{err.Error()}
+	for _, detail := range status.Convert(err).Details() {
+		if errorDetail, ok := detail.(*gateway.ErrorDetail); ok {
+			messages = append(messages, errorDetail.GetMessage())
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+// IsUserLocked reports whether the chaincode rejected the transaction because a user is locked
+func IsUserLocked(err error) bool {
+	return err != nil && strings.Contains(ErrorDetails(err), userLockedCode)
+}
+
+// IsAccessDenied reports whether the chaincode rejected the transaction because the caller lacks permission
+func IsAccessDenied(err error) bool {
+	return err != nil && strings.Contains(ErrorDetails(err), accessDeniedCode)
+}
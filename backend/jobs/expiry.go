@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"ams/backend/fabric"
+)
+
+// maxSweepRounds caps the chaincode calls made in a single tick when a backlog of
+// overdue transfers needs more than one batch
+const maxSweepRounds = 20
+
+// TransferExpirySweeper periodically expires overdue pending transfers on-chain.
+// It signs with a dedicated system identity (CA role "System"), not with an end user.
+type TransferExpirySweeper struct {
+	Fabric    *fabric.Service
+	Identity  string
+	Interval  time.Duration
+	BatchSize int
+}
+
+// expiryResult mirrors the chaincode ExpiryResult (only the fields the sweeper needs)
+type expiryResult struct {
+	Expired int  `json:"expired"`
+	HasMore bool `json:"hasMore"`
+}
+
+// Start runs the sweeper until the process exits. The resulting TransfersExpired
+// events are indexed into Postgres by the block listener, not here.
+func (s *TransferExpirySweeper) Start() {
+	log.Printf("⏰ Transfer expiry sweeper started (every %s as %s)", s.Interval, s.Identity)
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.sweep()
+	for range ticker.C {
+		s.sweep()
+	}
+}
+
+func (s *TransferExpirySweeper) sweep() {
+	contract, err := s.Fabric.GetContractForUser(s.Identity)
+	if err != nil {
+		log.Printf("⚠️ Expiry sweeper: failed to load identity %s: %v", s.Identity, err)
+		return
+	}
+
+	total := 0
+	for round := 0; round < maxSweepRounds; round++ {
+		result, err := contract.SubmitTransaction("ExpirePendingTransfers", strconv.Itoa(s.BatchSize))
+		if err != nil {
+			log.Printf("❌ Expiry sweeper: ExpirePendingTransfers failed: %s", fabric.ErrorDetails(err))
+			return
+		}
+
+		var res expiryResult
+		if err := json.Unmarshal(result, &res); err != nil {
+			log.Printf("⚠️ Expiry sweeper: failed to parse result: %v", err)
+			return
+		}
+		total += res.Expired
+		if !res.HasMore {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("⌛ Expiry sweeper: expired %d pending transfer(s)", total)
+	}
+}
@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"ams/backend/fabric"
+)
+
+// grantSweepLimit is the number of assets fetched per GetAssetsWithExpiredGrants call
+const grantSweepLimit = 50
+
+// GrantExpirySweeper periodically removes expired viewer grants on-chain.
+// Each asset is cleaned up in its own transaction, so every revocation reaches
+// the block listener as a regular AccessRevoked event.
+type GrantExpirySweeper struct {
+	Fabric   *fabric.Service
+	Identity string
+	Interval time.Duration
+}
+
+// Start runs the sweeper until the process exits
+func (s *GrantExpirySweeper) Start() {
+	log.Printf("⏰ Viewer grant sweeper started (every %s as %s)", s.Interval, s.Identity)
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.sweep()
+	for range ticker.C {
+		s.sweep()
+	}
+}
+
+func (s *GrantExpirySweeper) sweep() {
+	contract, err := s.Fabric.GetContractForUser(s.Identity)
+	if err != nil {
+		log.Printf("⚠️ Grant sweeper: failed to load identity %s: %v", s.Identity, err)
+		return
+	}
+
+	revoked := 0
+	for round := 0; round < maxSweepRounds; round++ {
+		result, err := contract.EvaluateTransaction("GetAssetsWithExpiredGrants", strconv.Itoa(grantSweepLimit))
+		if err != nil {
+			log.Printf("❌ Grant sweeper: GetAssetsWithExpiredGrants failed: %s", fabric.ErrorDetails(err))
+			return
+		}
+		var assetIDs []string
+		if err := json.Unmarshal(result, &assetIDs); err != nil {
+			log.Printf("⚠️ Grant sweeper: failed to parse result: %v", err)
+			return
+		}
+
+		cleaned := 0
+		for _, assetID := range assetIDs {
+			result, err := contract.SubmitTransaction("RevokeExpiredGrants", assetID)
+			if err != nil {
+				// Skip the asset (e.g. MVCC conflict with an owner update); the next tick retries it
+				log.Printf("❌ Grant sweeper: RevokeExpiredGrants(%s) failed: %s", assetID, fabric.ErrorDetails(err))
+				continue
+			}
+			n, _ := strconv.Atoi(string(result))
+			revoked += n
+			cleaned++
+		}
+		// A full page means more may remain, unless nothing could be cleaned this round
+		if len(assetIDs) < grantSweepLimit || cleaned == 0 {
+			break
+		}
+	}
+
+	if revoked > 0 {
+		log.Printf("⌛ Grant sweeper: revoked %d expired viewer grant(s)", revoked)
+	}
+}
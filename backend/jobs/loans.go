@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"ams/backend/fabric"
+)
+
+// LoanRecallSweeper automatically returns lent assets to their owners once they are overdue
+// by more than Grace. Until then they only show up in GetOverdueLoans, which gives borrowers
+// time to return them. Each recall is its own transaction and emits AssetRecalled.
+type LoanRecallSweeper struct {
+	Fabric   *fabric.Service
+	Identity string
+	Interval time.Duration
+	Grace    time.Duration
+}
+
+// overdueLoan mirrors the chaincode Asset (only the fields the sweeper needs)
+type overdueLoan struct {
+	ID           string    `json:"ID"`
+	Custodian    string    `json:"custodian"`
+	CustodyUntil int64     `json:"custodyUntil"`
+	Freeze       *struct{} `json:"freeze"` // Set while the asset is under a legal freeze
+}
+
+// Start runs the sweeper until the process exits
+func (s *LoanRecallSweeper) Start() {
+	log.Printf("⏰ Loan recall sweeper started (every %s as %s, grace %s)", s.Interval, s.Identity, s.Grace)
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.sweep()
+	for range ticker.C {
+		s.sweep()
+	}
+}
+
+func (s *LoanRecallSweeper) sweep() {
+	contract, err := s.Fabric.GetContractForUser(s.Identity)
+	if err != nil {
+		log.Printf("⚠️ Loan sweeper: failed to load identity %s: %v", s.Identity, err)
+		return
+	}
+
+	result, err := contract.EvaluateTransaction("GetOverdueLoans")
+	if err != nil {
+		log.Printf("❌ Loan sweeper: GetOverdueLoans failed: %s", fabric.ErrorDetails(err))
+		return
+	}
+	var loans []overdueLoan
+	if err := json.Unmarshal(result, &loans); err != nil {
+		log.Printf("⚠️ Loan sweeper: failed to parse result: %v", err)
+		return
+	}
+
+	cutoff := time.Now().Add(-s.Grace).Unix()
+	recalled := 0
+	for _, loan := range loans {
+		if loan.CustodyUntil > cutoff || loan.Freeze != nil {
+			continue
+		}
+		if _, err := contract.SubmitTransaction("RecallAsset", loan.ID); err != nil {
+			log.Printf("❌ Loan sweeper: RecallAsset(%s) failed: %s", loan.ID, fabric.ErrorDetails(err))
+			continue
+		}
+		log.Printf("↩️ Loan sweeper: recalled %s from %s (due %s)", loan.ID, loan.Custodian, time.Unix(loan.CustodyUntil, 0).UTC().Format(time.RFC3339))
+		recalled++
+	}
+
+	if recalled > 0 {
+		log.Printf("⌛ Loan sweeper: recalled %d overdue loan(s)", recalled)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"crypto/sha256"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -10,6 +11,9 @@ import (
 	"mime/multipart"
 	"net/http"
 	"database/sql"
+	"strconv"
+	"strings"
+	"time"
 
 	"os"
 	"github.com/gofiber/fiber/v2"
@@ -21,6 +25,7 @@ import (
 	"ams/backend/sync"
 	"ams/backend/auth"
 	"ams/backend/admin"
+	"ams/backend/jobs"
 )
 
 
@@ -72,18 +77,53 @@ func main() {
 		}
 	}
 
+	// Start Transfer Expiry Sweeper (signs as the System identity, see docs/OPERATIONS.md)
+	systemIdentity := os.Getenv("SYSTEM_IDENTITY")
+	if systemIdentity == "" {
+		systemIdentity = "system"
+	}
+	expiryInterval := envDuration("TRANSFER_EXPIRY_INTERVAL", 5*time.Minute)
+	sweeper := &jobs.TransferExpirySweeper{
+		Fabric:    fabService,
+		Identity:  systemIdentity,
+		Interval:  expiryInterval,
+		BatchSize: 50,
+	}
+	go sweeper.Start()
+
+	// Start Viewer Grant Sweeper (same System identity); removes time-limited grants once they expire
+	grantInterval := envDuration("GRANT_EXPIRY_INTERVAL", 5*time.Minute)
+	grantSweeper := &jobs.GrantExpirySweeper{
+		Fabric:   fabService,
+		Identity: systemIdentity,
+		Interval: grantInterval,
+	}
+	go grantSweeper.Start()
+
+	// Start Loan Recall Sweeper (same System identity); takes back loans overdue by more than the grace period
+	loanSweeper := &jobs.LoanRecallSweeper{
+		Fabric:   fabService,
+		Identity: systemIdentity,
+		Interval: envDuration("LOAN_RECALL_INTERVAL", 5*time.Minute),
+		Grace:    envDuration("LOAN_RECALL_GRACE", 24*time.Hour),
+	}
+	go loanSweeper.Start()
+
 
 	// Public Explorer Endpoint (PostgreSQL)
 	if pgDB != nil {
 		api.Get("/explorer/assets", func(c *fiber.Ctx) error {
 			search := c.Query("search")
 			owner := c.Query("owner")
+			holder := c.Query("holder") // Owner or holder of any share of a fractional asset
+			viewer := c.Query("viewer") // Owner, or granted view access directly, by role or by group
 			itemType := c.Query("type")
+			includeArchived := c.QueryBool("include_archived") // Archived (soft-deleted) assets are hidden by default
 
-			log.Printf("🔎 Explorer Query - Search: %s, Owner: %s, Type: %s", search, owner, itemType)
+			log.Printf("🔎 Explorer Query - Search: %s, Owner: %s, Holder: %s, Viewer: %s, Type: %s", search, owner, holder, viewer, itemType)
 
 			// Build Query
-			q := "SELECT id, name, asset_type, owner, status, metadata_url, last_tx_id, last_modified_by FROM assets WHERE 1=1"
+			q := "SELECT id, name, asset_type, owner, status, metadata_url, last_tx_id, last_modified_by, total_units, shares, custodian, attributes, frozen_case FROM assets WHERE 1=1"
 			args := []interface{}{}
 			argId := 1
 
@@ -97,11 +137,57 @@ func main() {
 				args = append(args, owner)
 				argId++
 			}
+			if holder != "" {
+				q += fmt.Sprintf(" AND (owner = $%d OR shares ? $%d)", argId, argId)
+				args = append(args, holder)
+				argId++
+			}
+			if viewer != "" {
+				// viewer_principals() (schema.sql) resolves roles and groups like the chaincode, active_viewers() drops
+				// expired grants like isActiveViewer; the ?| pre-filter keeps the viewers GIN index in use
+				q += fmt.Sprintf(" AND (owner = $%d OR (viewers ?| viewer_principals($%d) AND active_viewers(viewers, viewer_expiry) && viewer_principals($%d)))", argId, argId, argId)
+				args = append(args, viewer)
+				argId++
+			}
 			if itemType != "" {
 				q += fmt.Sprintf(" AND asset_type = $%d", argId)
 				args = append(args, itemType)
 				argId++
 			}
+			if !includeArchived {
+				q += " AND status <> 'Archived'"
+			}
+
+			// Custom attribute filters: attr.<name>=<value> matches exactly,
+			// attr_min.<name> and attr_max.<name> bound numeric attributes
+			var filterErr error
+			c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+				k, v := string(key), string(value)
+				switch {
+				case strings.HasPrefix(k, "attr."):
+					match, _ := json.Marshal(map[string]json.RawMessage{strings.TrimPrefix(k, "attr."): attributeFilterValue(v)})
+					q += fmt.Sprintf(" AND attributes @> $%d::jsonb", argId)
+					args = append(args, string(match))
+					argId++
+				case strings.HasPrefix(k, "attr_min."), strings.HasPrefix(k, "attr_max."):
+					bound, err := strconv.ParseFloat(v, 64)
+					if err != nil {
+						filterErr = fmt.Errorf("%s must be a number", k)
+						return
+					}
+					op := ">="
+					if strings.HasPrefix(k, "attr_max.") {
+						op = "<="
+					}
+					name := k[strings.Index(k, ".")+1:]
+					q += fmt.Sprintf(" AND jsonb_typeof(attributes->$%d) = 'number' AND (attributes->>$%d)::numeric %s $%d", argId, argId, op, argId+1)
+					args = append(args, name, bound)
+					argId += 2
+				}
+			})
+			if filterErr != nil {
+				return c.Status(400).JSON(fiber.Map{"error": filterErr.Error()})
+			}
 
 			q += " ORDER BY updated_at DESC LIMIT 50"
 
@@ -122,14 +208,27 @@ func main() {
 					MetadataURL    string
 					LastTxID       string
 					LastModifiedBy sql.NullString // Handle potential NULLs
+					TotalUnits     sql.NullInt64
+					Shares         []byte
+					Custodian      sql.NullString
+					Attributes     []byte
+					FrozenCase     sql.NullString
 				}
-				if err := rows.Scan(&r.ID, &r.Name, &r.Type, &r.Owner, &r.Status, &r.MetadataURL, &r.LastTxID, &r.LastModifiedBy); err != nil {
+				if err := rows.Scan(&r.ID, &r.Name, &r.Type, &r.Owner, &r.Status, &r.MetadataURL, &r.LastTxID, &r.LastModifiedBy, &r.TotalUnits, &r.Shares, &r.Custodian, &r.Attributes, &r.FrozenCase); err != nil {
 					continue
 				}
+				shares := map[string]int64{}
+				json.Unmarshal(r.Shares, &shares)
+				attributes := map[string]interface{}{}
+				json.Unmarshal(r.Attributes, &attributes)
 				results = append(results, map[string]interface{}{
 					"id": r.ID, "name": r.Name, "type": r.Type, "owner": r.Owner, 
 					"status": r.Status, "metadata_url": r.MetadataURL, "last_tx_id": r.LastTxID,
 					"last_modified_by": r.LastModifiedBy.String,
+					"total_units": r.TotalUnits.Int64, "shares": shares,
+					"custodian": r.Custodian.String,
+					"attributes": attributes,
+					"frozen": r.FrozenCase.Valid, "frozen_case": r.FrozenCase.String,
 				})
 			}
 			
@@ -288,6 +387,71 @@ func main() {
 		return fabService.GetContractForUser(userId)
 	}
 
+	// Helper to turn a failed chaincode transaction into an HTTP error.
+	// Locked users are refused by the chaincode itself and reported as 403.
+	txError := func(c *fiber.Ctx, err error, prefix string) error {
+		if fabric.IsUserLocked(err) {
+			return c.Status(403).JSON(fiber.Map{"error": "Account is Locked: " + fabric.ErrorDetails(err), "code": "USER_LOCKED"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": prefix + err.Error()})
+	}
+
+	// Helper behind the protected bulk import route: parses the upload, dry-runs the batch with
+	// ValidateAssetsBatch so every row gets a verdict, then submits it as one all-or-nothing transaction
+	importAssets := func(c *fiber.Ctx, contract *client.Contract) error {
+		rows, err := readAssetImport(c)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		// Rows carry no owner: the chaincode creates every asset for the submitting identity
+		for i := range rows {
+			rows[i].MetadataHash = fmt.Sprintf("%x", sha256.Sum256([]byte(rows[i].MetadataURL + rows[i].Name)))
+		}
+		batchJSON, _ := json.Marshal(rows)
+
+		validation, err := contract.EvaluateTransaction("ValidateAssetsBatch", string(batchJSON))
+		if err != nil {
+			return txError(c, err, "Failed to validate batch: ")
+		}
+		var verdicts []struct {
+			Index int    `json:"index"`
+			ID    string `json:"id"`
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(validation, &verdicts); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to parse validation result"})
+		}
+
+		results := make([]ImportRowResult, len(rows))
+		invalid := 0
+		for i, row := range rows {
+			results[i] = ImportRowResult{Row: i + 1, ID: row.ID, Status: "created"}
+		}
+		for _, v := range verdicts {
+			if v.Error != "" && v.Index < len(results) {
+				results[v.Index].Status = "invalid"
+				results[v.Index].Error = v.Error
+				invalid++
+			}
+		}
+		if invalid > 0 {
+			for i := range results {
+				if results[i].Status == "created" {
+					results[i].Status = "skipped"
+				}
+			}
+			return c.Status(422).JSON(fiber.Map{"error": fmt.Sprintf("%d of %d rows are invalid; nothing was imported", invalid, len(rows)), "created": 0, "rows": results})
+		}
+
+		log.Printf("Submitting Transaction: CreateAssetsBatch, %d assets", len(rows))
+		if _, err := contract.SubmitTransaction("CreateAssetsBatch", string(batchJSON)); err != nil {
+			return txError(c, err, "Failed to submit batch: ")
+		}
+
+		return c.JSON(fiber.Map{"message": "Assets imported successfully", "created": len(rows), "rows": results})
+	}
+
 	// --- AUTH SERVICE ---
 
 	// Login
@@ -406,6 +570,8 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 			Owner       string `json:"owner"` // Optional, defaults to JWT user
 			Status      string `json:"status"`
 			MetadataURL string `json:"metadata_url"`
+			Attributes  json.RawMessage `json:"attributes"` // Optional, checked against the registered asset type
+			PrivateDetails json.RawMessage `json:"private_details"` // Optional, stored in the private data collection
 		}
 
 		p := new(AssetRequest)
@@ -428,18 +594,14 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 
 		log.Printf("Submitting Transaction: CreateAsset, ID: %s", p.ID)
 		
-		_, err = contract.SubmitTransaction("CreateAsset", 
-			p.ID, 
-			p.Name, 
-			p.Type, 
-			p.Owner, 
-			p.Status, 
-			p.MetadataURL, 
-			metadataHash,
+		// Private details travel as transient data so they never reach the public ledger
+		_, err = contract.Submit("CreateAsset",
+			client.WithArguments(p.ID, p.Name, p.Type, p.Owner, p.Status, p.MetadataURL, metadataHash, attributesArg(p.Attributes)),
+			client.WithTransient(privateDetailsTransient(p.PrivateDetails)),
 		)
 
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Failed to submit transaction: " + err.Error()})
+			return txError(c, err, "Failed to submit transaction: ")
 		}
 
 		return c.JSON(fiber.Map{
@@ -450,6 +612,15 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 	})
 	
 	
+	// Bulk Import (Protected): CSV or JSON upload, created atomically for the JWT user
+	protected.Post("/assets/import", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{"error": "Auth failed: " + err.Error()})
+		}
+		return importAssets(c, contract)
+	})
+
 	// ========== MULTI-SIGNATURE TRANSFERS (CHAINCODE-BASED) ==========
 	// All multi-sig logic is now in the chaincode for true blockchain security
 	// Backend acts as a simple relay to the blockchain
@@ -462,8 +633,10 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 		}
 		
 		type InitiateTransferRequest struct {
-			AssetID  string `json:"asset_id"`
-			NewOwner string `json:"new_owner"`
+			AssetID        string  `json:"asset_id"`
+			NewOwner       string  `json:"new_owner"`
+			ExpiresInHours float64 `json:"expires_in_hours"` // Optional, 0 = asset type default
+			Price          int64   `json:"price"`            // Optional tokens the recipient pays on execution, 0 = no payment
 		}
 		p := new(InitiateTransferRequest)
 		if err := c.BodyParser(p); err != nil {
@@ -478,20 +651,41 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 		claims := c.Locals("user").(*auth.Claims)
 		log.Printf("📝 Initiating transfer: Asset %s from %s to %s", p.AssetID, claims.UserID, p.NewOwner)
 
-		// Call chaincode - pass current user as initiator
-		_, err = contract.SubmitTransaction("InitiateTransfer", p.AssetID, p.NewOwner, claims.UserID)
+		if p.ExpiresInHours < 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "expires_in_hours must be positive"})
+		}
+		expiresInSeconds := int64(p.ExpiresInHours * 3600)
+		if p.Price < 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "price cannot be negative"})
+		}
+
+		// Call chaincode - initiator is derived from the signing identity,
+		// the deadline is bounded by the asset type's expiry window on-chain
+		result, err := contract.SubmitTransaction("InitiateTransfer", p.AssetID, p.NewOwner, strconv.FormatInt(expiresInSeconds, 10), strconv.FormatInt(p.Price, 10))
 		if err != nil {
 			log.Printf("❌ Transfer initiation failed: %v", err)
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+			return txError(c, err, "")
 		}
 
-		log.Printf("✅ Transfer initiated on blockchain: Asset %s", p.AssetID)
+		var pending struct {
+			CreatedAt         int64 `json:"created_at"`
+			ExpiresAt         int64 `json:"expires_at"`
+			RequiredApprovals int   `json:"required_approvals"`
+		}
+		if err := json.Unmarshal(result, &pending); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to parse transfer result"})
+		}
+
+		log.Printf("✅ Transfer initiated on blockchain: Asset %s (expires %s)", p.AssetID, time.Unix(pending.ExpiresAt, 0).UTC().Format(time.RFC3339))
 
 		return c.JSON(fiber.Map{
 			"message": "Transfer initiated on blockchain. Awaiting recipient approval.",
 			"asset_id": p.AssetID,
 			"status": "PENDING",
-			"expires_in_hours": 24,
+			"expires_at": pending.ExpiresAt,
+			"expires_in_hours": float64(pending.ExpiresAt-pending.CreatedAt) / 3600,
+			"required_approvals": pending.RequiredApprovals,
+			"price": p.Price,
 		})
 	})
 
@@ -502,23 +696,457 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 
 		id := c.Params("id")
 		type AccessRequest struct {
-			ViewerID string `json:"viewer_id"`
+			ViewerID       string  `json:"viewer_id"`
+			Permission     string  `json:"permission"`       // "metadata" or "full" (default)
+			ExpiresInHours float64 `json:"expires_in_hours"` // Optional; 0 never expires
 		}
 		p := new(AccessRequest)
 		if err := c.BodyParser(p); err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
 		}
+		if p.ExpiresInHours < 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "expires_in_hours cannot be negative"})
+		}
+		durationSeconds := int64(p.ExpiresInHours * 3600)
 
-		log.Printf("Submitting Transaction: GrantAccess for Asset %s to %s", id, p.ViewerID)
-		_, err = contract.SubmitTransaction("GrantAccess", id, p.ViewerID)
+		log.Printf("Submitting Transaction: GrantAccess for Asset %s to %s (%s, %ds)", id, p.ViewerID, p.Permission, durationSeconds)
+		_, err = contract.SubmitTransaction("GrantAccess", id, p.ViewerID, p.Permission, strconv.FormatInt(durationSeconds, 10))
 
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Failed to grant access: " + err.Error()})
+			return txError(c, err, "Failed to grant access: ")
 		}
 
 		return c.JSON(fiber.Map{"message": "Access granted successfully"})
 	})
 
+	// Fractionalize Asset (Protected) - owner splits the asset into shares they hold entirely
+	protected.Post("/assets/:id/fractionalize", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		id := c.Params("id")
+		type FractionalizeRequest struct {
+			TotalUnits int64 `json:"total_units"`
+		}
+		p := new(FractionalizeRequest)
+		if err := c.BodyParser(p); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+		}
+		if p.TotalUnits < 2 {
+			return c.Status(400).JSON(fiber.Map{"error": "total_units must be at least 2"})
+		}
+
+		log.Printf("Submitting Transaction: FractionalizeAsset %s into %d units", id, p.TotalUnits)
+		_, err = contract.SubmitTransaction("FractionalizeAsset", id, strconv.FormatInt(p.TotalUnits, 10))
+		if err != nil {
+			return txError(c, err, "Failed to fractionalize asset: ")
+		}
+
+		return c.JSON(fiber.Map{"message": "Asset split into shares", "asset_id": id, "total_units": p.TotalUnits})
+	})
+
+	// Transfer Shares (Protected) - move part of the caller's holding to another user
+	protected.Post("/assets/:id/shares/transfer", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		id := c.Params("id")
+		type ShareTransferRequest struct {
+			Recipient string `json:"recipient"`
+			Units     int64  `json:"units"`
+		}
+		p := new(ShareTransferRequest)
+		if err := c.BodyParser(p); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+		}
+		if p.Recipient == "" || p.Units <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "recipient and a positive units are required"})
+		}
+
+		claims := c.Locals("user").(*auth.Claims)
+		log.Printf("📝 Transferring %d share(s) of %s from %s to %s", p.Units, id, claims.UserID, p.Recipient)
+		_, err = contract.SubmitTransaction("TransferShares", id, p.Recipient, strconv.FormatInt(p.Units, 10))
+		if err != nil {
+			return txError(c, err, "Failed to transfer shares: ")
+		}
+
+		return c.JSON(fiber.Map{"message": "Shares transferred", "asset_id": id, "recipient": p.Recipient, "units": p.Units})
+	})
+
+	// Lend Asset (Protected) - owner hands custody to a borrower until a due date; ownership is unchanged
+	protected.Post("/assets/:id/lend", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		id := c.Params("id")
+		type LendRequest struct {
+			Borrower      string  `json:"borrower"`
+			Until         int64   `json:"until"`          // Due date, Unix seconds
+			DurationHours float64 `json:"duration_hours"` // Alternative to until
+		}
+		p := new(LendRequest)
+		if err := c.BodyParser(p); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+		}
+		if p.Until == 0 && p.DurationHours > 0 {
+			p.Until = time.Now().Add(time.Duration(p.DurationHours * float64(time.Hour))).Unix()
+		}
+		if p.Borrower == "" || p.Until <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "borrower and until (or duration_hours) are required"})
+		}
+
+		log.Printf("🤝 Lending %s to %s until %s", id, p.Borrower, time.Unix(p.Until, 0).UTC().Format(time.RFC3339))
+		_, err = contract.SubmitTransaction("LendAsset", id, p.Borrower, strconv.FormatInt(p.Until, 10))
+		if err != nil {
+			return txError(c, err, "Failed to lend asset: ")
+		}
+
+		return c.JSON(fiber.Map{"message": "Asset lent", "asset_id": id, "custodian": p.Borrower, "until": p.Until})
+	})
+
+	// Return Asset (Protected) - the borrower hands a lent asset back
+	protected.Post("/assets/:id/return", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		id := c.Params("id")
+		if _, err := contract.SubmitTransaction("ReturnAsset", id); err != nil {
+			return txError(c, err, "Failed to return asset: ")
+		}
+		return c.JSON(fiber.Map{"message": "Asset returned", "asset_id": id})
+	})
+
+	// Recall Asset (Protected) - the owner takes a lent asset back early
+	protected.Post("/assets/:id/recall", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		id := c.Params("id")
+		if _, err := contract.SubmitTransaction("RecallAsset", id); err != nil {
+			return txError(c, err, "Failed to recall asset: ")
+		}
+		return c.JSON(fiber.Map{"message": "Asset recalled", "asset_id": id})
+	})
+
+	// Attest Asset (Protected) - auditors record an attestation backed by a document hash; the role is enforced on-chain
+	protected.Post("/assets/:id/attestations", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		id := c.Params("id")
+		type AttestRequest struct {
+			Type         string  `json:"type"`
+			DocumentHash string  `json:"document_hash"` // SHA-256 of the supporting document, hex
+			ValidUntil   int64   `json:"valid_until"`   // Unix seconds
+			ValidDays    float64 `json:"valid_days"`    // Alternative to valid_until
+		}
+		p := new(AttestRequest)
+		if err := c.BodyParser(p); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+		}
+		if p.ValidUntil == 0 && p.ValidDays > 0 {
+			p.ValidUntil = time.Now().Add(time.Duration(p.ValidDays * float64(24*time.Hour))).Unix()
+		}
+		if p.Type == "" || p.DocumentHash == "" || p.ValidUntil <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "type, document_hash and valid_until (or valid_days) are required"})
+		}
+
+		claims := c.Locals("user").(*auth.Claims)
+		log.Printf("🔏 %s attests %s on %s until %s", claims.UserID, p.Type, id, time.Unix(p.ValidUntil, 0).UTC().Format(time.RFC3339))
+		result, err := contract.SubmitTransaction("AttestAsset", id, p.Type, p.DocumentHash, strconv.FormatInt(p.ValidUntil, 10))
+		if err != nil {
+			return txError(c, err, "Failed to attest asset: ")
+		}
+		c.Set("Content-Type", "application/json")
+		return c.Send(result)
+	})
+
+	// Token Balance (Protected) - the caller's own balance, read from the ledger
+	protected.Get("/tokens/balance", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		claims := c.Locals("user").(*auth.Claims)
+		result, err := contract.EvaluateTransaction("GetTokenBalance", claims.UserID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": fabric.ErrorDetails(err)})
+		}
+		c.Set("Content-Type", "application/json")
+		return c.Send(result)
+	})
+
+	// Token Balance of another account (Protected) - auditors and admins only, enforced on-chain
+	protected.Get("/tokens/balance/:account", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		result, err := contract.EvaluateTransaction("GetTokenBalance", c.Params("account"))
+		if err != nil {
+			if fabric.IsAccessDenied(err) {
+				return c.Status(403).JSON(fiber.Map{"error": fabric.ErrorDetails(err)})
+			}
+			return txError(c, err, "Failed to read balance: ")
+		}
+		c.Set("Content-Type", "application/json")
+		return c.Send(result)
+	})
+
+	// Transfer Tokens (Protected) - pay another user from the caller's balance
+	protected.Post("/tokens/transfer", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		type TokenTransferRequest struct {
+			To     string `json:"to"`
+			Amount int64  `json:"amount"`
+		}
+		p := new(TokenTransferRequest)
+		if err := c.BodyParser(p); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+		}
+		if p.To == "" || p.Amount <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "to and a positive amount are required"})
+		}
+
+		claims := c.Locals("user").(*auth.Claims)
+		log.Printf("🪙 Token transfer: %d from %s to %s", p.Amount, claims.UserID, p.To)
+		result, err := contract.SubmitTransaction("TransferTokens", p.To, strconv.FormatInt(p.Amount, 10))
+		if err != nil {
+			return txError(c, err, "Failed to transfer tokens: ")
+		}
+		c.Set("Content-Type", "application/json")
+		return c.Send(result)
+	})
+
+	// Token Movements (Protected, PostgreSQL) - the caller's mints, payments and settlements.
+	// Auditors and admins may pass ?account= to read another account's movements.
+	if pgDB != nil {
+		protected.Get("/tokens/movements", func(c *fiber.Ctx) error {
+			claims := c.Locals("user").(*auth.Claims)
+			account := claims.UserID
+			if requested := c.Query("account"); requested != "" && requested != account {
+				if claims.Role != "Admin" && claims.Role != "Auditor" {
+					return c.Status(403).JSON(fiber.Map{"error": "Only auditors and admins can read other accounts"})
+				}
+				account = requested
+			}
+
+			rows, err := pgDB.Query(`
+				SELECT tx_id, kind, COALESCE(from_account, ''), to_account, amount, COALESCE(asset_id, ''), COALESCE(actor_id, ''), timestamp
+				FROM token_movements
+				WHERE from_account = $1 OR to_account = $1
+				ORDER BY timestamp DESC, id DESC
+				LIMIT 100
+			`, account)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch token movements: " + err.Error()})
+			}
+			defer rows.Close()
+
+			movements := []map[string]interface{}{}
+			for rows.Next() {
+				var txID, kind, from, to, assetID, actorID string
+				var amount int64
+				var timestamp time.Time
+				if err := rows.Scan(&txID, &kind, &from, &to, &amount, &assetID, &actorID, &timestamp); err != nil {
+					log.Printf("Error scanning token movement row: %v", err)
+					continue
+				}
+				movements = append(movements, map[string]interface{}{
+					"tx_id":     txID,
+					"kind":      kind,
+					"from":      from,
+					"to":        to,
+					"amount":    amount,
+					"asset_id":  assetID,
+					"actor_id":  actorID,
+					"timestamp": timestamp,
+				})
+			}
+			return c.JSON(movements)
+		})
+
+		// Attestations across assets (Protected, PostgreSQL) - auditors and admins.
+		// ?state=expired lists lapsed attestations to renew, ?expiring_within_days=N those about to lapse.
+		protected.Get("/attestations", func(c *fiber.Ctx) error {
+			claims := c.Locals("user").(*auth.Claims)
+			if claims.Role != "Admin" && claims.Role != "Auditor" {
+				return c.Status(403).JSON(fiber.Map{"error": "Only auditors and admins can list attestations"})
+			}
+
+			q := `SELECT id, asset_id, attestation_type, document_hash, auditor, auditor_msp, issued_at, valid_until
+				FROM asset_attestations WHERE 1=1`
+			args := []interface{}{}
+			switch c.Query("state") {
+			case "":
+			case "current":
+				q += " AND valid_until >= NOW()"
+			case "expired":
+				q += " AND valid_until < NOW()"
+			default:
+				return c.Status(400).JSON(fiber.Map{"error": "state must be current or expired"})
+			}
+			if days := c.QueryInt("expiring_within_days"); days > 0 {
+				args = append(args, days)
+				q += fmt.Sprintf(" AND valid_until >= NOW() AND valid_until < NOW() + make_interval(days => $%d)", len(args))
+			}
+			if t := c.Query("type"); t != "" {
+				args = append(args, t)
+				q += fmt.Sprintf(" AND attestation_type = $%d", len(args))
+			}
+			q += " ORDER BY valid_until ASC LIMIT 200"
+
+			rows, err := pgDB.Query(q, args...)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch attestations: " + err.Error()})
+			}
+			defer rows.Close()
+
+			attestations := []map[string]interface{}{}
+			for rows.Next() {
+				var id, assetID, attestationType, documentHash, auditor, auditorMSP string
+				var issuedAt, validUntil time.Time
+				if err := rows.Scan(&id, &assetID, &attestationType, &documentHash, &auditor, &auditorMSP, &issuedAt, &validUntil); err != nil {
+					log.Printf("Error scanning attestation row: %v", err)
+					continue
+				}
+				attestations = append(attestations, map[string]interface{}{
+					"id":            id,
+					"asset_id":      assetID,
+					"type":          attestationType,
+					"document_hash": documentHash,
+					"auditor":       auditor,
+					"auditor_msp":   auditorMSP,
+					"issued_at":     issuedAt,
+					"valid_until":   validUntil,
+				})
+			}
+			return c.JSON(attestations)
+		})
+	}
+
+	// Archive Asset (Protected) - soft delete by the owner or an admin; only admins can restore or purge
+	protected.Delete("/assets/:id", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		id := c.Params("id")
+		var p struct {
+			Reason string `json:"reason"`
+		}
+		c.BodyParser(&p)
+		if p.Reason == "" {
+			p.Reason = c.Query("reason")
+		}
+		if strings.TrimSpace(p.Reason) == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "reason is required"})
+		}
+
+		claims := c.Locals("user").(*auth.Claims)
+		log.Printf("🗄️ Archiving %s by %s: %s", id, claims.UserID, p.Reason)
+		if _, err := contract.SubmitTransaction("ArchiveAsset", id, p.Reason); err != nil {
+			return txError(c, err, "Failed to archive asset: ")
+		}
+		return c.JSON(fiber.Map{"message": "Asset archived", "asset_id": id, "status": "Archived"})
+	})
+
+	// Attach Asset (Protected) - bundle a child asset under a parent the caller owns
+	protected.Post("/assets/:id/children", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		id := c.Params("id")
+		type AttachRequest struct {
+			ChildID string `json:"child_id"`
+		}
+		p := new(AttachRequest)
+		if err := c.BodyParser(p); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+		}
+		if p.ChildID == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "child_id is required"})
+		}
+
+		log.Printf("Submitting Transaction: AttachAsset %s -> %s", p.ChildID, id)
+		_, err = contract.SubmitTransaction("AttachAsset", id, p.ChildID)
+		if err != nil {
+			return txError(c, err, "Failed to attach asset: ")
+		}
+
+		return c.JSON(fiber.Map{"message": "Asset attached", "parent_id": id, "child_id": p.ChildID})
+	})
+
+	// Detach Asset (Protected)
+	protected.Delete("/assets/:id/children/:childId", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		id, childID := c.Params("id"), c.Params("childId")
+		log.Printf("Submitting Transaction: DetachAsset %s from %s", childID, id)
+		_, err = contract.SubmitTransaction("DetachAsset", id, childID)
+		if err != nil {
+			return txError(c, err, "Failed to detach asset: ")
+		}
+
+		return c.JSON(fiber.Map{"message": "Asset detached", "parent_id": id, "child_id": childID})
+	})
+
+	// Set Private Details (Protected) - owner only, body is the details object
+	protected.Put("/assets/:id/private", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		id := c.Params("id")
+		if !json.Valid(c.Body()) {
+			return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+		}
+
+		log.Printf("Submitting Transaction: SetAssetPrivateDetails for Asset %s", id)
+		_, err = contract.Submit("SetAssetPrivateDetails",
+			client.WithArguments(id),
+			client.WithTransient(privateDetailsTransient(c.Body())),
+		)
+		if err != nil {
+			return txError(c, err, "Failed to set private details: ")
+		}
+
+		return c.JSON(fiber.Map{"message": "Private details saved", "asset_id": id})
+	})
+
+	// Read Private Details (Protected) - owner and granted viewers only, enforced by the chaincode
+	protected.Get("/assets/:id/private", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		result, err := contract.EvaluateTransaction("ReadAssetPrivateDetails", c.Params("id"))
+		if err != nil {
+			details := fabric.ErrorDetails(err)
+			if strings.Contains(details, "not allowed") {
+				return c.Status(403).JSON(fiber.Map{"error": details})
+			}
+			return c.Status(404).JSON(fiber.Map{"error": "Failed to read private details: " + details})
+		}
+
+		c.Set("Content-Type", "application/json")
+		return c.Send(result)
+	})
+
+	// Get Transfer Policy - the approval policy a new transfer of the asset would use
+	protected.Get("/assets/:id/transfer-policy", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { 
+			return c.Status(401).JSON(fiber.Map{"error": err.Error()}) 
+		}
+
+		result, err := contract.EvaluateTransaction("GetTransferPolicy", c.Params("id"))
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Failed to get transfer policy: " + fabric.ErrorDetails(err)})
+		}
+
+		c.Set("Content-Type", "application/json")
+		return c.Send(result)
+	})
+
 	// Get Pending Transfers - Query from blockchain
 	protected.Get("/transfers/pending", func(c *fiber.Ctx) error {
 		contract, err := getContract(c)
@@ -528,29 +1156,44 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 
 		claims := c.Locals("user").(*auth.Claims)
 
-		// Query all pending transfers from blockchain
-		result, err := contract.EvaluateTransaction("GetAllPendingTransfers")
+		pageSize, bookmark, err := pageParams(c)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		// Query one page of pending transfers from blockchain
+		result, err := contract.EvaluateTransaction("GetPendingTransfersWithPagination", strconv.Itoa(int(pageSize)), bookmark)
 		if err != nil {
 			log.Printf("❌ Failed to get pending transfers: %v", err)
 			return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch pending transfers: " + err.Error()})
 		}
 
-		var allPending []map[string]interface{}
-		if err := json.Unmarshal(result, &allPending); err != nil {
+		var page struct {
+			Records  []map[string]interface{} `json:"records"`
+			Bookmark string                   `json:"bookmark"`
+		}
+		if err := json.Unmarshal(result, &page); err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": "Failed to parse pending transfers"})
 		}
+		allPending := page.Records
 
-		// Filter for current user (either as current_owner or new_owner)
+		// Filter for current user (current_owner, new_owner or a co-signer named by the transfer policy)
 		var userPending []map[string]interface{}
 		for _, p := range allPending {
 			currentOwner, _ := p["current_owner"].(string)
 			newOwner, _ := p["new_owner"].(string)
+			isApprover := isPolicyApprover(p["policy"], claims.UserID, claims.Role)
 			
-			if currentOwner == claims.UserID || newOwner == claims.UserID {
+			if currentOwner == claims.UserID || newOwner == claims.UserID || isApprover {
 				// Add helper fields for frontend
 				approvals, _ := p["approvals"].([]interface{})
 				p["approval_count"] = len(approvals)
 				p["is_recipient"] = (newOwner == claims.UserID)
+				p["is_approver"] = isApprover && newOwner != claims.UserID && currentOwner != claims.UserID
+				// Transfers initiated before approval policies existed need 2 signatures
+				if required, _ := p["required_approvals"].(float64); required == 0 {
+					p["required_approvals"] = 2
+				}
 				
 				// Check if user has already signed
 				hasSigned := false
@@ -572,7 +1215,11 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 			userPending = []map[string]interface{}{}
 		}
 
-		return c.JSON(userPending)
+		return c.JSON(fiber.Map{
+			"records":  userPending,
+			"bookmark": page.Bookmark,
+			"has_more": page.Bookmark != "",
+		})
 	})
 
 	// Approve Transfer - Approve on blockchain
@@ -587,18 +1234,34 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 
 		log.Printf("✅ Approving transfer: Asset %s by %s", assetID, claims.UserID)
 
-		// Call chaincode - pass current user as approver
-		_, err = contract.SubmitTransaction("ApproveTransfer", assetID, claims.UserID)
+		// Call chaincode - approver is derived from the signing identity
+		result, err := contract.SubmitTransaction("ApproveTransfer", assetID)
 		if err != nil {
 			log.Printf("❌ Transfer approval failed: %v", err)
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+			return txError(c, err, "")
 		}
 
-		log.Printf("✅ Transfer approved on blockchain: Asset %s", assetID)
+		// Expired and invalidated transfers are committed (releasing the asset) rather than failing
+		var pending struct {
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal(result, &pending); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to parse transfer result"})
+		}
+		switch pending.Status {
+		case "EXPIRED":
+			log.Printf("⌛ Transfer expired before approval: Asset %s", assetID)
+			return c.Status(410).JSON(fiber.Map{"error": "Transfer request has expired", "status": pending.Status})
+		case "INVALID":
+			log.Printf("⚠️ Transfer invalidated: Asset %s", assetID)
+			return c.Status(409).JSON(fiber.Map{"error": "Asset ownership changed or asset was removed; transfer invalidated", "status": pending.Status})
+		}
+
+		log.Printf("✅ Transfer approved on blockchain: Asset %s (%s)", assetID, pending.Status)
 
 		return c.JSON(fiber.Map{
 			"message": "Transfer approved successfully",
-			"status": "APPROVED",
+			"status": pending.Status,
 		})
 	})
 
@@ -624,11 +1287,11 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 
 		log.Printf("❌ Rejecting transfer: Asset %s by %s. Reason: %s", assetID, claims.UserID, p.Reason)
 
-		// Call chaincode - pass current user as rejector
-		_, err = contract.SubmitTransaction("RejectTransfer", assetID, p.Reason, claims.UserID)
+		// Call chaincode - rejector is derived from the signing identity
+		_, err = contract.SubmitTransaction("RejectTransfer", assetID, p.Reason)
 		if err != nil {
 			log.Printf("❌ Transfer rejection failed: %v", err)
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+			return txError(c, err, "")
 		}
 
 		log.Printf("✅ Transfer rejected on blockchain: Asset %s", assetID)
@@ -639,6 +1302,39 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 		})
 	})
 
+	// Cancel Transfer - Initiator withdraws a pending transfer
+	protected.Post("/transfers/:assetId/cancel", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { 
+			return c.Status(401).JSON(fiber.Map{"error": err.Error()}) 
+		}
+
+		assetID := c.Params("assetId")
+		claims := c.Locals("user").(*auth.Claims)
+
+		type CancelRequest struct {
+			Reason string `json:"reason"`
+		}
+		p := new(CancelRequest)
+		c.BodyParser(p)
+
+		log.Printf("🚫 Cancelling transfer: Asset %s by %s", assetID, claims.UserID)
+
+		// Call chaincode - only the initiator (current owner) may cancel
+		_, err = contract.SubmitTransaction("CancelTransfer", assetID, p.Reason)
+		if err != nil {
+			log.Printf("❌ Transfer cancellation failed: %v", err)
+			return txError(c, err, "")
+		}
+
+		log.Printf("✅ Transfer cancelled on blockchain: Asset %s", assetID)
+
+		return c.JSON(fiber.Map{
+			"message": "Transfer cancelled successfully",
+			"status": "CANCELLED",
+		})
+	})
+
 
 	// Update Asset (Protected)
 	protected.Put("/assets/:id", func(c *fiber.Ctx) error {
@@ -650,6 +1346,7 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 			Name        string `json:"name"`
 			Status      string `json:"status"`
 			MetadataURL string `json:"metadata_url"`
+			Attributes  json.RawMessage `json:"attributes"` // Optional; omitted keeps the current attributes, {} clears them
 		}
 		p := new(UpdateAssetRequest)
 		if err := c.BodyParser(p); err != nil {
@@ -673,6 +1370,25 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 			return c.Status(403).JSON(fiber.Map{"error": "Only asset owner or admin can update"})
 		}
 
+		// Validate the status transition early against the chaincode state machine
+		currentStatus, _ := currentAsset["status"].(string)
+		if currentStatus == "Pending Transfer" {
+			return c.Status(409).JSON(fiber.Map{"error": "Asset is locked by a pending transfer"})
+		}
+		if p.Status != currentStatus {
+			rulesResult, err := contract.EvaluateTransaction("GetAssetStatusRules")
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Failed to load status rules: " + err.Error()})
+			}
+			var rules []StatusRule
+			if err := json.Unmarshal(rulesResult, &rules); err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Failed to parse status rules"})
+			}
+			if !isAllowedStatusTransition(rules, currentStatus, p.Status) {
+				return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Invalid status transition from %s to %s", currentStatus, p.Status)})
+			}
+		}
+
 		// Calculate new metadata hash
 		metadataHash := fmt.Sprintf("%x", sha256.Sum256([]byte(p.MetadataURL + p.Name)))
 
@@ -690,10 +1406,11 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 			p.Status, 
 			p.MetadataURL, 
 			metadataHash,
+			attributesArg(p.Attributes),
 		)
 
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Failed to update asset: " + err.Error()})
+			return txError(c, err, "Failed to update asset: ")
 		}
 
 		return c.JSON(fiber.Map{
@@ -707,6 +1424,7 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 	api.Get("/assets", func(c *fiber.Ctx) error {
 		userId := c.Query("user_id")
 		userRole := c.Query("user_role")
+		includeArchived := c.QueryBool("include_archived") // Archived (soft-deleted) assets are hidden by default
 
 		// If JWT is present in header, prefer it?
 		// Note: Frontend might not send bearer for this public-ish view yet.
@@ -716,42 +1434,66 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 			return c.Status(401).JSON(fiber.Map{"error": "Authentication failed: " + err.Error()})
 		}
 
-		log.Printf("Evaluating Transaction: GetAllAssets for User: %s (%s)", userId, userRole)
-		evaluateResult, err := contract.EvaluateTransaction("GetAllAssets")
+		pageSize, bookmark, err := pageParams(c)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		log.Printf("Evaluating Transaction: GetAssetsWithPagination(%d) for User: %s (%s)", pageSize, userId, userRole)
+		evaluateResult, err := contract.EvaluateTransaction("GetAssetsWithPagination", strconv.Itoa(int(pageSize)), bookmark)
 
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
 		
-        var allAssets []map[string]interface{}
-        if err := json.Unmarshal(evaluateResult, &allAssets); err != nil {
+        var page struct {
+            Records  []map[string]interface{} `json:"records"`
+            Bookmark string                   `json:"bookmark"`
+        }
+        if err := json.Unmarshal(evaluateResult, &page); err != nil {
              return c.Status(500).JSON(fiber.Map{"error": "Failed to parse chaincode response"})
         }
 
-        var visibleAssets []map[string]interface{}
-        for _, asset := range allAssets {
+        // Resolve the user's role and group memberships the same way the chaincode does
+        var principals []string
+        if userId != "" {
+            if result, err := contract.EvaluateTransaction("GetViewerPrincipals", userId); err == nil {
+                json.Unmarshal(result, &principals)
+            } else {
+                log.Printf("⚠️ GetViewerPrincipals(%s) failed: %s", userId, fabric.ErrorDetails(err))
+            }
+        }
+        if len(principals) == 0 {
+            principals = []string{userId, "EVERYONE"}
+        }
+
+        // Visibility is filtered per page, so a page may hold fewer than page_size records;
+        // keep following the bookmark until it is empty
+        visibleAssets := []map[string]interface{}{}
+        now := time.Now().Unix()
+        for _, asset := range page.Records {
             owner, _ := asset["owner"].(string)
-            viewersInterface, ok := asset["viewers"].([]interface{})
-            
-            isViewer := false
-            if ok {
-                for _, v := range viewersInterface {
-                    if s, ok := v.(string); ok && (s == userId || s == "EVERYONE") {
-                        isViewer = true
-                        break
-                    }
-                }
+            custodian, _ := asset["custodian"].(string)
+            shares, _ := asset["shares"].(map[string]interface{})
+            _, isHolder := shares[userId]
+            isViewer := isActiveViewer(asset, principals, now)
+            if status, _ := asset["status"].(string); status == "Archived" && !includeArchived {
+                continue
             }
             
             // Check Access
             if userRole == "Admin" {
                  visibleAssets = append(visibleAssets, asset)
-            } else if owner == userId || isViewer {
+            } else if owner == userId || custodian == userId || isHolder || isViewer {
                  visibleAssets = append(visibleAssets, asset)
             }
         }
 
-		return c.JSON(visibleAssets)
+		return c.JSON(fiber.Map{
+			"records":  visibleAssets,
+			"bookmark": page.Bookmark,
+			"has_more": page.Bookmark != "",
+		})
 	})
 
 	// Create Asset (Legacy Unprotected - Keep for scripts?)
@@ -768,6 +1510,7 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 			Owner       string `json:"owner"`
 			Status      string `json:"status"`
 			MetadataURL string `json:"metadata_url"`
+			Attributes  json.RawMessage `json:"attributes"`
 		}
 
 		p := new(AssetRequest)
@@ -787,15 +1530,138 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 		metadataHash := fmt.Sprintf("%x", sha256.Sum256([]byte(p.MetadataURL + p.Name)))
 		log.Printf("Submitting Transaction: CreateAsset (Public), ID: %s", p.ID)
 		
-		_, err = contract.SubmitTransaction("CreateAsset", p.ID, p.Name, p.Type, p.Owner, p.Status, p.MetadataURL, metadataHash)
+		_, err = contract.SubmitTransaction("CreateAsset", p.ID, p.Name, p.Type, p.Owner, p.Status, p.MetadataURL, metadataHash, attributesArg(p.Attributes))
 
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Failed to submit transaction: " + err.Error()})
+			return txError(c, err, "Failed to submit transaction: ")
 		}
 
 		return c.JSON(fiber.Map{"message": "Asset created successfully", "id": p.ID})
 	})
 
+	// Get Asset Types - registered types and the custom attributes their assets carry
+	api.Get("/asset-types", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		evaluateResult, err := contract.EvaluateTransaction("GetAllAssetTypes")
+		if err != nil { return c.Status(500).JSON(fiber.Map{"error": fabric.ErrorDetails(err)}) }
+		c.Set("Content-Type", "application/json")
+		return c.Send(evaluateResult)
+	})
+
+	api.Get("/asset-types/:name", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		evaluateResult, err := contract.EvaluateTransaction("GetAssetType", c.Params("name"))
+		if err != nil { return c.Status(404).JSON(fiber.Map{"error": fabric.ErrorDetails(err)}) }
+		c.Set("Content-Type", "application/json")
+		return c.Send(evaluateResult)
+	})
+
+	// Get Asset Status Rules (state machine used to validate status changes)
+	api.Get("/assets/status-rules", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		evaluateResult, err := contract.EvaluateTransaction("GetAssetStatusRules")
+		if err != nil { return c.Status(500).JSON(fiber.Map{"error": err.Error()}) }
+		c.Set("Content-Type", "application/json")
+		return c.Send(evaluateResult)
+	})
+
+	// Get Asset Attestations - ?state=current (default) or expired, read from the ledger
+	api.Get("/assets/:id/attestations", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		txName := "GetCurrentAttestations"
+		switch c.Query("state", "current") {
+		case "current":
+		case "expired":
+			txName = "GetExpiredAttestations"
+		default:
+			return c.Status(400).JSON(fiber.Map{"error": "state must be current or expired"})
+		}
+		evaluateResult, err := contract.EvaluateTransaction(txName, c.Params("id"))
+		if err != nil { return c.Status(404).JSON(fiber.Map{"error": fabric.ErrorDetails(err)}) }
+		c.Set("Content-Type", "application/json")
+		return c.Send(evaluateResult)
+	})
+
+	// Get Asset Tree - the whole bundle an asset belongs to, from its top-level parent down
+	api.Get("/assets/:id/tree", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		evaluateResult, err := contract.EvaluateTransaction("GetAssetTree", c.Params("id"))
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": fabric.ErrorDetails(err)})
+		}
+		c.Set("Content-Type", "application/json")
+		return c.Send(evaluateResult)
+	})
+
+	// Get Asset Holdings - each holder's units and percentage (a whole asset is one 100% holding)
+	api.Get("/assets/:id/holdings", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		evaluateResult, err := contract.EvaluateTransaction("GetAssetHoldings", c.Params("id"))
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": fabric.ErrorDetails(err)})
+		}
+		c.Set("Content-Type", "application/json")
+		return c.Send(evaluateResult)
+	})
+
+	// Get Overdue Loans - lent assets past their due date, most overdue first
+	api.Get("/loans/overdue", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		evaluateResult, err := contract.EvaluateTransaction("GetOverdueLoans")
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": fabric.ErrorDetails(err)})
+		}
+		c.Set("Content-Type", "application/json")
+		return c.Send(evaluateResult)
+	})
+
+	// Get Private Details Hash - public, lets anyone check details handed over off-chain
+	api.Get("/assets/:id/private-hash", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		id := c.Params("id")
+		result, err := contract.EvaluateTransaction("GetAssetPrivateDetailsHash", id)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": fabric.ErrorDetails(err)})
+		}
+		return c.JSON(fiber.Map{"asset_id": id, "hash": string(result)})
+	})
+
+	// Verify Private Details - body is the details object as returned by GET /protected/assets/:id/private
+	api.Post("/assets/:id/private-hash/verify", func(c *fiber.Ctx) error {
+		contract, err := getContract(c)
+		if err != nil { return c.Status(401).JSON(fiber.Map{"error": err.Error()}) }
+
+		id := c.Params("id")
+		if !json.Valid(c.Body()) {
+			return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+		}
+
+		result, err := contract.Evaluate("VerifyAssetPrivateDetails",
+			client.WithArguments(id),
+			client.WithTransient(privateDetailsTransient(c.Body())),
+		)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": fabric.ErrorDetails(err)})
+		}
+		return c.JSON(fiber.Map{"asset_id": id, "valid": string(result) == "true"})
+	})
+
 	// Get Asset History
 	api.Get("/assets/:id/history", func(c *fiber.Ctx) error {
 		contract, err := getContract(c)
@@ -825,11 +1691,16 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 			return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
 		}
 
+		// "@" separates the org domain in certificate names; a username containing it could pass for another user on-chain
+		if p.Username == "" || strings.Contains(p.Username, "@") {
+			return c.Status(400).JSON(fiber.Map{"error": "username is required and must not contain '@'"})
+		}
+
 		log.Printf("🔹 WALLET: Register request for %s", p.Username)
 
 		// 1. Enroll with CA
 		caClient := fabric.NewCAClient()
-		err := caClient.RegisterAndEnroll(p.Username, p.Password)
+		err := caClient.RegisterAndEnroll(p.Username, p.Password, "User")
 		if err != nil {
 			log.Printf("❌ WALLET: CA Registration failed: %v", err)
 			return c.Status(500).JSON(fiber.Map{"error": "CA Registration failed: " + err.Error()})
@@ -914,7 +1785,7 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 		)
 
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Failed to register user: " + err.Error()})
+			return txError(c, err, "Failed to register user: ")
 		}
 		
 		// Upsert PII to DB
@@ -959,3 +1830,254 @@ api.Post("/auth/set-password", func(c *fiber.Ctx) error {
 	// Start server (Async)
 	log.Fatal(app.Listen(":3000"))
 }
+
+// Cursor pagination defaults for ledger list routes (?page_size=&bookmark=)
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// envDuration reads a Go duration (e.g. "5m") from the environment, falling back on a missing or invalid value
+func envDuration(name string, fallback time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("⚠️ Invalid %s %q, using %s", name, v, fallback)
+		return fallback
+	}
+	return d
+}
+
+// pageParams reads the page_size and bookmark query parameters of a paginated route
+func pageParams(c *fiber.Ctx) (int32, string, error) {
+	pageSize := defaultPageSize
+	if v := c.Query("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxPageSize {
+			return 0, "", fmt.Errorf("page_size must be between 1 and %d", maxPageSize)
+		}
+		pageSize = n
+	}
+	return int32(pageSize), c.Query("bookmark"), nil
+}
+
+// attributesArg turns an optional attributes object from a request body into the chaincode argument;
+// an absent or null value becomes "" (no attributes on create, keep the current ones on update)
+func attributesArg(raw json.RawMessage) string {
+	if trimmed := strings.TrimSpace(string(raw)); trimmed != "" && trimmed != "null" {
+		return trimmed
+	}
+	return ""
+}
+
+// attributeFilterValue reads an explorer attr.<name> value as JSON (numbers, booleans)
+// and falls back to a plain string
+func attributeFilterValue(v string) json.RawMessage {
+	if json.Valid([]byte(v)) {
+		return json.RawMessage(v)
+	}
+	quoted, _ := json.Marshal(v)
+	return quoted
+}
+
+// privateDetailsTransient wraps asset private details in the transient map the chaincode reads.
+// An empty or null body yields an empty map, so no private details are written.
+func privateDetailsTransient(details []byte) map[string][]byte {
+	transient := map[string][]byte{}
+	if len(details) > 0 && string(details) != "null" {
+		transient["asset_private_details"] = details
+	}
+	return transient
+}
+
+// maxImportRows mirrors the chaincode's CreateAssetsBatch limit
+const maxImportRows = 100
+
+// AssetImportRow is one asset of a bulk import, as read from a CSV or JSON upload
+type AssetImportRow struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Status       string `json:"status"`
+	MetadataURL  string `json:"metadata_url"`
+	MetadataHash string `json:"metadata_hash"`
+	Attributes   json.RawMessage `json:"attributes,omitempty"` // JSON object; in CSV, an "attributes" column holding JSON
+}
+
+// ImportRowResult reports the outcome of one uploaded row; Row is 1-based and excludes the CSV header
+type ImportRowResult struct {
+	Row    int    `json:"row"`
+	ID     string `json:"id"`
+	Status string `json:"status"` // "created", "invalid" or "skipped" (valid, but the batch was rejected)
+	Error  string `json:"error,omitempty"`
+}
+
+// readAssetImport reads the rows of a bulk import. The upload is either a multipart "file"
+// (.csv or .json) or the raw request body, told apart by its content type.
+func readAssetImport(c *fiber.Ctx) ([]AssetImportRow, error) {
+	data := c.Body()
+	isCSV := strings.HasPrefix(c.Get("Content-Type"), "text/csv")
+
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file")
+		}
+		defer file.Close()
+		if data, err = io.ReadAll(file); err != nil {
+			return nil, fmt.Errorf("failed to read file")
+		}
+		isCSV = strings.HasSuffix(strings.ToLower(fileHeader.Filename), ".csv")
+	}
+
+	var rows []AssetImportRow
+	if isCSV {
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+		if len(records) < 2 {
+			return nil, fmt.Errorf("the CSV needs a header line and at least one row")
+		}
+		columns := map[string]int{}
+		for i, name := range records[0] {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		if _, ok := columns["id"]; !ok {
+			return nil, fmt.Errorf("the CSV header must contain an id column")
+		}
+		field := func(record []string, name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		for i, record := range records[1:] {
+			row := AssetImportRow{
+				ID:          field(record, "id"),
+				Name:        field(record, "name"),
+				Type:        field(record, "type"),
+				Status:      field(record, "status"),
+				MetadataURL: field(record, "metadata_url"),
+			}
+			if attributes := field(record, "attributes"); attributes != "" {
+				if !json.Valid([]byte(attributes)) {
+					return nil, fmt.Errorf("row %d: attributes must be a JSON object", i+1)
+				}
+				row.Attributes = json.RawMessage(attributes)
+			}
+			rows = append(rows, row)
+		}
+	} else if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("invalid JSON, expected an array of assets: %v", err)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("the upload contains no assets")
+	}
+	if len(rows) > maxImportRows {
+		return nil, fmt.Errorf("the upload contains %d assets (max %d per import)", len(rows), maxImportRows)
+	}
+	return rows, nil
+}
+
+// isActiveViewer reports whether one of the user's principals (their ID, "role:<Role>", "group:<name>"
+// or EVERYONE, as returned by GetViewerPrincipals) is a viewer of the asset whose grant has not expired.
+// It mirrors the chaincode check, since expired grants stay on the ledger until the grant sweeper removes them.
+func isActiveViewer(asset map[string]interface{}, principals []string, now int64) bool {
+	matches := map[string]bool{}
+	for _, p := range principals {
+		matches[p] = true
+	}
+
+	expiresAt := map[string]int64{}
+	grants, _ := asset["grants"].([]interface{})
+	for _, g := range grants {
+		grant, _ := g.(map[string]interface{})
+		viewer, _ := grant["viewer"].(string)
+		if exp, ok := grant["expiresAt"].(float64); ok {
+			expiresAt[viewer] = int64(exp)
+		}
+	}
+
+	viewers, _ := asset["viewers"].([]interface{})
+	for _, v := range viewers {
+		viewer, ok := v.(string)
+		if !ok || !matches[viewer] {
+			continue
+		}
+		if exp := expiresAt[viewer]; exp == 0 || now < exp {
+			return true
+		}
+	}
+	return false
+}
+
+// isPolicyApprover reports whether the user may co-sign a transfer under its policy snapshot,
+// either by name or through their role
+func isPolicyApprover(policy interface{}, userID string, role string) bool {
+	policyMap, ok := policy.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	users, _ := policyMap["approverUsers"].([]interface{})
+	for _, u := range users {
+		if u == userID {
+			return true
+		}
+	}
+	roles, _ := policyMap["approverRoles"].([]interface{})
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusRule is one entry of the chaincode asset status state machine
+type StatusRule struct {
+	Status        string   `json:"status"`
+	Transferable  bool     `json:"transferable"`
+	AllowedNext   []string `json:"allowedNext"`
+	SystemManaged bool     `json:"systemManaged"`
+}
+
+// isAllowedStatusTransition mirrors the chaincode check so invalid updates fail before endorsement.
+// Unknown (legacy) current statuses may move to any valid status.
+// System-managed statuses (e.g. Pending Transfer) can neither be entered nor left by an update.
+func isAllowedStatusTransition(rules []StatusRule, from string, to string) bool {
+	known := false
+	valid := false
+	for _, rule := range rules {
+		if rule.Status == to {
+			valid = !rule.SystemManaged
+		}
+		if rule.Status == from {
+			if rule.SystemManaged {
+				return false
+			}
+			known = true
+		}
+	}
+	if !valid {
+		return false
+	}
+	if !known {
+		return true
+	}
+	for _, rule := range rules {
+		if rule.Status != from {
+			continue
+		}
+		for _, next := range rule.AllowedNext {
+			if next == to {
+				return true
+			}
+		}
+	}
+	return false
+}
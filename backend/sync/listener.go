@@ -31,9 +31,41 @@ type Asset struct {
 	MetadataURL    string   `json:"metadata_url"`
 	MetadataHash   string   `json:"metadata_hash"`
 	Viewers        []string `json:"viewers"`
+	Grants         []*ViewerGrant `json:"grants,omitempty"` // Permission and expiry per viewer
 	UpdatedAt      int64    `json:"updatedAt"`
 	LastModifiedBy string   `json:"lastModifiedBy"`
 	Sequence       uint64   `json:"sequence"`
+	StatusBeforeTransfer string `json:"statusBeforeTransfer,omitempty"`
+	TotalUnits     int64            `json:"totalUnits,omitempty"` // Fractional assets only
+	Shares         map[string]int64 `json:"shares,omitempty"`     // Holder -> units, fractional assets only
+	ParentID       string           `json:"parentId,omitempty"`   // Bundle parent, if attached
+	Children       []string         `json:"children,omitempty"`   // Attached assets
+	Custodian      string           `json:"custodian,omitempty"`    // Borrower, while on loan
+	CustodyUntil   int64            `json:"custodyUntil,omitempty"` // Loan due date
+	ArchivedAt     int64            `json:"archivedAt,omitempty"`    // Archive (soft delete) time
+	ArchivedBy     string           `json:"archivedBy,omitempty"`
+	ArchiveReason  string           `json:"archiveReason,omitempty"`
+	Attributes     map[string]interface{} `json:"attributes,omitempty"` // Custom attributes of the asset type
+	Freeze         *FreezeRecord    `json:"freeze,omitempty"`          // Active legal freeze
+}
+
+// ViewerGrant matches the chaincode structure: what one viewer may see and until when
+type ViewerGrant struct {
+	Viewer     string `json:"viewer"`
+	Permission string `json:"permission"`
+	ExpiresAt  int64  `json:"expiresAt,omitempty"` // Unix seconds; 0 never expires
+	GrantedBy  string `json:"grantedBy"`
+	GrantedAt  int64  `json:"grantedAt"`
+}
+
+// FreezeRecord matches the chaincode structure: one freeze or unfreeze of an asset
+type FreezeRecord struct {
+	Action    string `json:"action"` // FREEZE or UNFREEZE
+	CaseRef   string `json:"caseRef"`
+	Reason    string `json:"reason"`
+	Actor     string `json:"actor"`
+	Timestamp int64  `json:"timestamp"`
+	TxID      string `json:"txId"`
 }
 
 // User structure matching chaincode (No PII)
@@ -57,6 +89,22 @@ type PendingTransfer struct {
 	ExpiresAt       int64      `json:"expires_at"`       
 	ExecutedAt      int64      `json:"executed_at"`
 	RejectionReason string     `json:"rejection_reason"`
+	CancelledBy        string `json:"cancelled_by"`
+	CancellationReason string `json:"cancellation_reason"`
+	Price              int64  `json:"price"` // Tokens paid on execution, 0 = no payment
+}
+
+// TokenMovement matches the chaincode structure; balances are the ones after the movement
+type TokenMovement struct {
+	Kind        string `json:"kind"` // MINT, TRANSFER or SETTLEMENT
+	From        string `json:"from"` // Empty for mints
+	To          string `json:"to"`
+	Amount      int64  `json:"amount"`
+	FromBalance int64  `json:"fromBalance"`
+	ToBalance   int64  `json:"toBalance"`
+	AssetID     string `json:"assetId"`
+	Actor       string `json:"actor"`
+	Timestamp   int64  `json:"timestamp"`
 }
 
 func (bl *BlockListener) StartEventListening() {
@@ -72,14 +120,47 @@ func (bl *BlockListener) StartEventListening() {
 		log.Printf("📨 Received Event: %s (Tx: %s, Block: %d)", event.EventName, event.TransactionID, event.BlockNumber)
 
 		switch event.EventName {
-		case "AssetCreated", "AssetUpdated", "AccessGranted", "AccessRevoked", "AssetTransferred":
+		case "AssetCreated", "AssetUpdated", "AssetStatusChanged", "AccessGranted", "AccessRevoked", "AssetTransferred", "AssetFractionalized", "AssetArchived", "AssetRestored":
 			processAssetEvent(bl.DB, event)
-		case "TransferInitiated", "TransferApproved", "TransferExecuted", "TransferRejected":
+		case "AssetsCreated":
+			processAssetsCreatedEvent(bl.DB, event)
+		case "SharesTransferred":
+			processSharesTransferredEvent(bl.DB, event)
+		case "AssetLent", "AssetReturned", "AssetRecalled":
+			processCustodyEvent(bl.DB, event)
+		case "AssetAttested":
+			processAttestationEvent(bl.DB, event)
+		case "AssetFrozen", "AssetUnfrozen":
+			processFreezeEvent(bl.DB, event)
+		case "AssetForceTransferred":
+			processForceTransferEvent(bl.DB, event)
+		case "AssetAttached", "AssetDetached":
+			processBundleEvent(bl.DB, event)
+		case "TransferInitiated", "TransferApproved", "TransferExecuted", "TransferRejected", "TransferExpired", "TransferInvalidated", "TransferCancelled":
 			processTransferEvent(bl.DB, event)
+		case "TransfersExpired":
+			processTransfersExpiredEvent(bl.DB, event)
+		case "AssetPurged":
+			processPurgeEvent(bl.DB, event)
 		case "AssetDeleted":
+			// Emitted by hard deletes before archiving existed
 			processDeleteEvent(bl.DB, event)
+		case "AssetPrivateDetailsSet":
+			processPrivateDetailsEvent(bl.DB, event)
 		case "UserCreated", "UserStatusUpdated":
 			processUserEvent(bl.DB, event)
+		case "GroupUpdated", "GroupDeleted":
+			processGroupEvent(bl.DB, event)
+		case "TokensMinted", "TokensTransferred":
+			processTokenEvent(bl.DB, event)
+		case "AssetTypeSet", "AssetTypeDeleted":
+			processAssetTypeEvent(bl.DB, event)
+		case "TransferPolicySet", "TransferPolicyDeleted", "ExpiryWindowSet":
+			// Configuration is read from the ledger on demand; log for the audit trail only
+			log.Printf("📜 %s: %s", event.EventName, string(event.Payload))
+		case "LedgerMigrated":
+			// Key layout change only; documents are unchanged so nothing to re-index
+			log.Printf("🔁 Ledger keys migrated to composite layout: %s", string(event.Payload))
 		default:
 			log.Printf("❓ Unknown Event: %s", event.EventName)
 		}
@@ -141,25 +222,403 @@ func processUserEvent(db *sql.DB, event *client.ChaincodeEvent) {
 	}
 }
 
+// processGroupEvent mirrors viewer group membership into GROUP_MEMBERS, which viewer_principals() reads.
+// GroupUpdated carries the whole group; GroupDeleted only its ID.
+func processGroupEvent(db *sql.DB, event *client.ChaincodeEvent) {
+	var group struct {
+		ID      string   `json:"id"`
+		Members []string `json:"members"`
+	}
+	if event.EventName == "GroupDeleted" {
+		group.ID = string(event.Payload)
+	} else if err := json.Unmarshal(event.Payload, &group); err != nil {
+		log.Printf("⚠️ Failed to parse group payload: %v", err)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("❌ DB Error (Begin): %v", err)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM group_members WHERE group_id = $1", group.ID); err != nil {
+		log.Printf("❌ DB Error (Delete Group Members): %v", err)
+		return
+	}
+	for _, member := range group.Members {
+		if _, err := tx.Exec("INSERT INTO group_members (group_id, user_id) VALUES ($1, $2)", group.ID, member); err != nil {
+			log.Printf("❌ DB Error (Insert Group Member): %v", err)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("❌ DB Error (Commit): %v", err)
+		return
+	}
+	log.Printf("👥 Synced group %s (%d members)", group.ID, len(group.Members))
+}
+
+// processAssetTypeEvent mirrors the asset type registry into ASSET_TYPES.
+// AssetTypeSet carries the whole definition; AssetTypeDeleted only its name.
+func processAssetTypeEvent(db *sql.DB, event *client.ChaincodeEvent) {
+	if event.EventName == "AssetTypeDeleted" {
+		name := string(event.Payload)
+		if _, err := db.Exec("DELETE FROM asset_types WHERE name = $1", name); err != nil {
+			log.Printf("❌ DB Error (Delete Asset Type): %v", err)
+			return
+		}
+		log.Printf("🏷️ Removed asset type %s", name)
+		return
+	}
+
+	var def struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		Attributes  json.RawMessage `json:"attributes"`
+		UpdatedAt   int64           `json:"updatedAt"`
+		UpdatedBy   string          `json:"updatedBy"`
+		Sequence    uint64          `json:"sequence"`
+	}
+	if err := json.Unmarshal(event.Payload, &def); err != nil {
+		log.Printf("⚠️ Failed to parse asset type payload: %v", err)
+		return
+	}
+	if len(def.Attributes) == 0 || string(def.Attributes) == "null" {
+		def.Attributes = json.RawMessage("[]")
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO asset_types (name, description, attributes, updated_at, updated_by, sequence)
+		VALUES ($1, $2, $3, to_timestamp($4), $5, $6)
+		ON CONFLICT (name) DO UPDATE SET
+			description = EXCLUDED.description,
+			attributes = EXCLUDED.attributes,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by,
+			sequence = EXCLUDED.sequence
+		WHERE asset_types.sequence < EXCLUDED.sequence`,
+		def.Name, def.Description, []byte(def.Attributes), def.UpdatedAt, def.UpdatedBy, def.Sequence)
+	if err != nil {
+		log.Printf("❌ DB Error (Upsert Asset Type): %v", err)
+		return
+	}
+	log.Printf("🏷️ Synced asset type %s", def.Name)
+}
+
 func processAssetEvent(db *sql.DB, event *client.ChaincodeEvent) {
 	var asset Asset
 	if err := json.Unmarshal(event.Payload, &asset); err != nil {
 		log.Printf("⚠️ Failed to parse asset payload: %v", err)
 		return
 	}
+	// IDs are only unique per document type on the ledger, so never index a non-asset document as an asset
+	if asset.DocType != "" && asset.DocType != "asset" {
+		log.Printf("⚠️ Ignoring %s payload with docType %q", event.EventName, asset.DocType)
+		return
+	}
+
+	// 1-2. Sequence check and upsert into ASSETS table
+	if !upsertAsset(db, &asset, event.TransactionID) {
+		return
+	}
+
+	// 3. Insert into ASSET_HISTORY table
+	historyQuery := `
+		INSERT INTO asset_history (tx_id, asset_id, action_type, from_owner, to_owner, block_number, timestamp, actor_id, asset_snapshot)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), $7, $8)
+	`
+	// Map event name to action type
+	actionType := strings.ToUpper(strings.Replace(event.EventName, "Asset", "", 1))
+	if event.EventName == "AccessGranted" { actionType = "GRANT_ACCESS" }
+	if event.EventName == "AccessRevoked" { actionType = "REVOKE_ACCESS" }
+	if event.EventName == "AssetStatusChanged" { actionType = "STATUS_CHANGE" }
+
+	// For simple logic, we just use current owner as 'to_owner'. 'from_owner' would require previous state query, ignoring for sync simplicity or using DB trigger
+	_, err := db.Exec(historyQuery, event.TransactionID, asset.ID, actionType, "", asset.Owner, event.BlockNumber, asset.LastModifiedBy, event.Payload)
+
+	if err != nil {
+		log.Printf("❌ DB Error (Insert History): %v", err)
+	} else {
+		log.Printf("✅ Synced Asset %s to Postgres (Seq: %d)", asset.ID, asset.Sequence)
+	}
+}
+
+// processAssetsCreatedEvent syncs a CreateAssetsBatch transaction: one CREATED history row per asset
+func processAssetsCreatedEvent(db *sql.DB, event *client.ChaincodeEvent) {
+	var batch struct {
+		Assets []*Asset `json:"assets"`
+	}
+	if err := json.Unmarshal(event.Payload, &batch); err != nil {
+		log.Printf("⚠️ Failed to parse AssetsCreated payload: %v", err)
+		return
+	}
+
+	for _, asset := range batch.Assets {
+		if !upsertAsset(db, asset, event.TransactionID) {
+			continue
+		}
+		snapshot, _ := json.Marshal(asset)
+		_, err := db.Exec(`
+			INSERT INTO asset_history (tx_id, asset_id, action_type, from_owner, to_owner, block_number, timestamp, actor_id, asset_snapshot)
+			VALUES ($1, $2, 'CREATED', '', $3, $4, NOW(), $5, $6)
+		`, event.TransactionID, asset.ID, asset.Owner, event.BlockNumber, asset.LastModifiedBy, snapshot)
+		if err != nil {
+			log.Printf("❌ DB Error (Insert History): %v", err)
+		}
+	}
+	log.Printf("📦 Synced batch of %d asset(s)", len(batch.Assets))
+}
 
+// processSharesTransferredEvent syncs a partial transfer of a fractional asset.
+// History records the giving and receiving holder rather than the (largest-holder) owner.
+func processSharesTransferredEvent(db *sql.DB, event *client.ChaincodeEvent) {
+	var payload struct {
+		Asset *Asset `json:"asset"`
+		From  string `json:"from"`
+		To    string `json:"to"`
+		Units int64  `json:"units"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.Asset == nil {
+		log.Printf("⚠️ Failed to parse shares payload: %v", err)
+		return
+	}
+
+	if !upsertAsset(db, payload.Asset, event.TransactionID) {
+		return
+	}
+
+	snapshot, _ := json.Marshal(payload.Asset)
+	_, err := db.Exec(`
+		INSERT INTO asset_history (tx_id, asset_id, action_type, from_owner, to_owner, block_number, timestamp, actor_id, asset_snapshot)
+		VALUES ($1, $2, 'SHARE_TRANSFER', $3, $4, $5, NOW(), $6, $7)
+	`, event.TransactionID, payload.Asset.ID, payload.From, payload.To, event.BlockNumber, payload.From, snapshot)
+
+	if err != nil {
+		log.Printf("❌ DB Error (Insert History): %v", err)
+	} else {
+		log.Printf("✅ Synced %d share(s) of %s from %s to %s", payload.Units, payload.Asset.ID, payload.From, payload.To)
+	}
+}
+
+// processCustodyEvent syncs a loan starting or ending. History records the hand-over:
+// owner -> borrower for LEND, borrower -> owner for RETURN and RECALL.
+func processCustodyEvent(db *sql.DB, event *client.ChaincodeEvent) {
+	var payload struct {
+		Asset     *Asset `json:"asset"`
+		Custodian string `json:"custodian"`
+		Until     int64  `json:"until"`
+		Actor     string `json:"actor"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.Asset == nil {
+		log.Printf("⚠️ Failed to parse custody payload: %v", err)
+		return
+	}
+
+	if !upsertAsset(db, payload.Asset, event.TransactionID) {
+		return
+	}
+
+	actionType, from, to := "LEND", payload.Asset.Owner, payload.Custodian
+	switch event.EventName {
+	case "AssetReturned":
+		actionType, from, to = "RETURN", payload.Custodian, payload.Asset.Owner
+	case "AssetRecalled":
+		actionType, from, to = "RECALL", payload.Custodian, payload.Asset.Owner
+	}
+	snapshot, _ := json.Marshal(payload.Asset)
+	_, err := db.Exec(`
+		INSERT INTO asset_history (tx_id, asset_id, action_type, from_owner, to_owner, block_number, timestamp, actor_id, asset_snapshot)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), $7, $8)
+	`, event.TransactionID, payload.Asset.ID, actionType, from, to, event.BlockNumber, payload.Actor, snapshot)
+
+	if err != nil {
+		log.Printf("❌ DB Error (Insert History): %v", err)
+	} else {
+		log.Printf("🤝 %s %s: %s -> %s", actionType, payload.Asset.ID, from, to)
+	}
+}
+
+// processAttestationEvent syncs the attested asset, records an ATTEST history row
+// and indexes the attestation in ASSET_ATTESTATIONS
+func processAttestationEvent(db *sql.DB, event *client.ChaincodeEvent) {
+	var payload struct {
+		Asset       *Asset `json:"asset"`
+		Attestation *struct {
+			ID              string `json:"id"`
+			Type            string `json:"type"`
+			DocumentHash    string `json:"documentHash"`
+			Auditor         string `json:"auditor"`
+			AuditorMSP      string `json:"auditorMsp"`
+			CertificateHash string `json:"certificateHash"`
+			IssuedAt        int64  `json:"issuedAt"`
+			ValidUntil      int64  `json:"validUntil"`
+		} `json:"attestation"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.Asset == nil || payload.Attestation == nil {
+		log.Printf("⚠️ Failed to parse attestation payload: %v", err)
+		return
+	}
+	a := payload.Attestation
+
+	_, err := db.Exec(`
+		INSERT INTO asset_attestations (id, asset_id, attestation_type, document_hash, auditor, auditor_msp, certificate_hash, issued_at, valid_until, block_number)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, to_timestamp($8), to_timestamp($9), $10)
+		ON CONFLICT (id) DO NOTHING
+	`, a.ID, payload.Asset.ID, a.Type, a.DocumentHash, a.Auditor, a.AuditorMSP, a.CertificateHash, a.IssuedAt, a.ValidUntil, event.BlockNumber)
+	if err != nil {
+		log.Printf("❌ DB Error (Insert Attestation): %v", err)
+	}
+
+	if !upsertAsset(db, payload.Asset, event.TransactionID) {
+		return
+	}
+
+	snapshot, _ := json.Marshal(payload.Asset)
+	_, err = db.Exec(`
+		INSERT INTO asset_history (tx_id, asset_id, action_type, from_owner, to_owner, block_number, timestamp, actor_id, asset_snapshot)
+		VALUES ($1, $2, 'ATTEST', $3, $3, $4, NOW(), $5, $6)
+	`, event.TransactionID, payload.Asset.ID, payload.Asset.Owner, event.BlockNumber, a.Auditor, snapshot)
+	if err != nil {
+		log.Printf("❌ DB Error (Insert History): %v", err)
+	} else {
+		log.Printf("🔏 %s attested %s on %s", a.Auditor, a.Type, payload.Asset.ID)
+	}
+}
+
+// processFreezeEvent syncs a frozen or unfrozen asset, appends the change to ASSET_FREEZES,
+// records a FREEZE/UNFREEZE history row and syncs the pending transfer it held or resumed
+func processFreezeEvent(db *sql.DB, event *client.ChaincodeEvent) {
+	var payload struct {
+		Asset    *Asset           `json:"asset"`
+		Record   *FreezeRecord    `json:"record"`
+		Transfer *PendingTransfer `json:"transfer"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.Asset == nil || payload.Record == nil {
+		log.Printf("⚠️ Failed to parse freeze payload: %v", err)
+		return
+	}
+	r := payload.Record
+
+	_, err := db.Exec(`
+		INSERT INTO asset_freezes (tx_id, asset_id, action, case_ref, reason, actor_id, timestamp, block_number)
+		VALUES ($1, $2, $3, $4, $5, $6, to_timestamp($7), $8)
+		ON CONFLICT (tx_id) DO NOTHING
+	`, event.TransactionID, payload.Asset.ID, r.Action, r.CaseRef, r.Reason, r.Actor, r.Timestamp, event.BlockNumber)
+	if err != nil {
+		log.Printf("❌ DB Error (Insert Freeze): %v", err)
+	}
+	if payload.Transfer != nil {
+		syncPendingTransfer(db, payload.Transfer)
+	}
+
+	if !upsertAsset(db, payload.Asset, event.TransactionID) {
+		return
+	}
+
+	snapshot, _ := json.Marshal(payload.Asset)
+	_, err = db.Exec(`
+		INSERT INTO asset_history (tx_id, asset_id, action_type, from_owner, to_owner, block_number, timestamp, actor_id, asset_snapshot)
+		VALUES ($1, $2, $3, $4, $4, $5, NOW(), $6, $7)
+	`, event.TransactionID, payload.Asset.ID, r.Action, payload.Asset.Owner, event.BlockNumber, r.Actor, snapshot)
+	if err != nil {
+		log.Printf("❌ DB Error (Insert History): %v", err)
+	} else {
+		log.Printf("🧊 %s %s under case %s by %s", r.Action, payload.Asset.ID, r.CaseRef, r.Actor)
+	}
+}
+
+// processForceTransferEvent syncs an admin's forced transfer and the pending transfer it cancelled,
+// recording a FORCE_TRANSFER history row that carries the reason and the document hash
+func processForceTransferEvent(db *sql.DB, event *client.ChaincodeEvent) {
+	var payload struct {
+		Asset             *Asset           `json:"asset"`
+		PreviousOwner     string           `json:"previousOwner"`
+		NewOwner          string           `json:"newOwner"`
+		Reason            string           `json:"reason"`
+		DocumentHash      string           `json:"documentHash"`
+		Actor             string           `json:"actor"`
+		CancelledTransfer *PendingTransfer `json:"cancelledTransfer"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.Asset == nil {
+		log.Printf("⚠️ Failed to parse force transfer payload: %v", err)
+		return
+	}
+	if payload.CancelledTransfer != nil {
+		syncPendingTransfer(db, payload.CancelledTransfer)
+	}
+
+	if !upsertAsset(db, payload.Asset, event.TransactionID) {
+		return
+	}
+
+	snapshot, _ := json.Marshal(payload.Asset)
+	details, _ := json.Marshal(map[string]interface{}{
+		"reason":       payload.Reason,
+		"documentHash": payload.DocumentHash,
+	})
+	_, err := db.Exec(`
+		INSERT INTO asset_history (tx_id, asset_id, action_type, from_owner, to_owner, block_number, timestamp, actor_id, asset_snapshot, details)
+		VALUES ($1, $2, 'FORCE_TRANSFER', $3, $4, $5, NOW(), $6, $7, $8)
+	`, event.TransactionID, payload.Asset.ID, payload.PreviousOwner, payload.NewOwner, event.BlockNumber, payload.Actor, snapshot, details)
+	if err != nil {
+		log.Printf("❌ DB Error (Insert History): %v", err)
+	} else {
+		log.Printf("⚖️ %s force-transferred %s from %s to %s: %s", payload.Actor, payload.Asset.ID, payload.PreviousOwner, payload.NewOwner, payload.Reason)
+	}
+}
+
+// processBundleEvent syncs both sides of an attach/detach; history is recorded on the child
+func processBundleEvent(db *sql.DB, event *client.ChaincodeEvent) {
+	var payload struct {
+		Parent *Asset `json:"parent"`
+		Child  *Asset `json:"child"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.Parent == nil || payload.Child == nil {
+		log.Printf("⚠️ Failed to parse bundle payload: %v", err)
+		return
+	}
+
+	upsertAsset(db, payload.Parent, event.TransactionID)
+	if !upsertAsset(db, payload.Child, event.TransactionID) {
+		return
+	}
+
+	actionType := "ATTACH"
+	if event.EventName == "AssetDetached" {
+		actionType = "DETACH"
+	}
+	snapshot, _ := json.Marshal(payload.Child)
+	_, err := db.Exec(`
+		INSERT INTO asset_history (tx_id, asset_id, action_type, block_number, timestamp, actor_id, asset_snapshot)
+		VALUES ($1, $2, $3, $4, NOW(), $5, $6)
+	`, event.TransactionID, payload.Child.ID, actionType, event.BlockNumber, payload.Child.LastModifiedBy, snapshot)
+
+	if err != nil {
+		log.Printf("❌ DB Error (Insert History): %v", err)
+	} else {
+		log.Printf("🔗 %s %s <- %s", actionType, payload.Parent.ID, payload.Child.ID)
+	}
+}
+
+// upsertAsset writes the asset state into the ASSETS table unless a newer sequence is already stored.
+// It reports whether the caller should go on and record history for the event.
+func upsertAsset(db *sql.DB, asset *Asset, txID string) bool {
 	// 1. Sequence Check
 	var currentSeq uint64
 	err := db.QueryRow("SELECT sequence FROM assets WHERE id = $1", asset.ID).Scan(&currentSeq)
 	if err == nil && asset.Sequence < currentSeq {
 		log.Printf("⚠️ Stale Asset Event: Seq %d < Current %d for %s", asset.Sequence, currentSeq, asset.ID)
-		return
+		return false
 	}
 
 	// 2. Upsert into ASSETS table
 	query := `
-		INSERT INTO assets (id, doc_type, name, asset_type, owner, status, metadata_url, metadata_hash, viewers, last_tx_id, last_modified_by, updated_at, sequence)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, to_timestamp($12), $13)
+		INSERT INTO assets (id, doc_type, name, asset_type, owner, status, metadata_url, metadata_hash, viewers, last_tx_id, last_modified_by, updated_at, sequence, total_units, shares, parent_id, custodian, custody_until, archived_at, archived_by, archive_reason, attributes, frozen_case, frozen_at, frozen_by, viewer_expiry)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, to_timestamp($12), $13, $14, $15, NULLIF($16, ''), NULLIF($17, ''), CASE WHEN $18::BIGINT > 0 THEN to_timestamp($18) END,
+			CASE WHEN $19::BIGINT > 0 THEN to_timestamp($19) END, NULLIF($20, ''), NULLIF($21, ''), $22,
+			NULLIF($23, ''), CASE WHEN $24::BIGINT > 0 THEN to_timestamp($24) END, NULLIF($25, ''), $26)
 		ON CONFLICT (id) DO UPDATE SET
 			name = EXCLUDED.name,
 			asset_type = EXCLUDED.asset_type,
@@ -171,105 +630,314 @@ func processAssetEvent(db *sql.DB, event *client.ChaincodeEvent) {
 			last_tx_id = EXCLUDED.last_tx_id,
 			last_modified_by = EXCLUDED.last_modified_by,
 			updated_at = EXCLUDED.updated_at,
-			sequence = EXCLUDED.sequence
+			sequence = EXCLUDED.sequence,
+			total_units = EXCLUDED.total_units,
+			shares = EXCLUDED.shares,
+			parent_id = EXCLUDED.parent_id,
+			custodian = EXCLUDED.custodian,
+			custody_until = EXCLUDED.custody_until,
+			archived_at = EXCLUDED.archived_at,
+			archived_by = EXCLUDED.archived_by,
+			archive_reason = EXCLUDED.archive_reason,
+			attributes = EXCLUDED.attributes,
+			frozen_case = EXCLUDED.frozen_case,
+			frozen_at = EXCLUDED.frozen_at,
+			frozen_by = EXCLUDED.frozen_by,
+			viewer_expiry = EXCLUDED.viewer_expiry
 		WHERE assets.sequence < EXCLUDED.sequence;
 	`
 	viewersJSON, _ := json.Marshal(asset.Viewers)
+	// Only time-limited grants are stored; viewers missing from the map never expire
+	viewerExpiry := map[string]int64{}
+	for _, grant := range asset.Grants {
+		if grant != nil && grant.ExpiresAt > 0 {
+			viewerExpiry[grant.Viewer] = grant.ExpiresAt
+		}
+	}
+	viewerExpiryJSON, _ := json.Marshal(viewerExpiry)
+	// Whole assets are stored with an empty share map; the owner column covers them
+	shares := asset.Shares
+	if shares == nil {
+		shares = map[string]int64{}
+	}
+	sharesJSON, _ := json.Marshal(shares)
+	attributes := asset.Attributes
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+	attributesJSON, _ := json.Marshal(attributes)
+	freeze := asset.Freeze
+	if freeze == nil {
+		freeze = &FreezeRecord{}
+	}
 	
 	_, err = db.Exec(query, 
 		asset.ID, asset.DocType, asset.Name, asset.Type, asset.Owner, 
 		asset.Status, asset.MetadataURL, asset.MetadataHash, viewersJSON,
-		event.TransactionID, asset.LastModifiedBy, asset.UpdatedAt, asset.Sequence,
+		txID, asset.LastModifiedBy, asset.UpdatedAt, asset.Sequence,
+		asset.TotalUnits, sharesJSON, asset.ParentID,
+		asset.Custodian, asset.CustodyUntil,
+		asset.ArchivedAt, asset.ArchivedBy, asset.ArchiveReason,
+		attributesJSON,
+		freeze.CaseRef, freeze.Timestamp, freeze.Actor,
+		viewerExpiryJSON,
 	)
 
 	if err != nil {
 		log.Printf("❌ DB Error (Upsert Asset): %v", err)
-		return
+		return false
 	}
+	return true
+}
 
-	// 3. Insert into ASSET_HISTORY table
-	historyQuery := `
-		INSERT INTO asset_history (tx_id, asset_id, action_type, from_owner, to_owner, block_number, timestamp, actor_id, asset_snapshot)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), $7, $8)
-	`
-	// Map event name to action type
-	actionType := strings.ToUpper(strings.Replace(event.EventName, "Asset", "", 1))
-	if event.EventName == "AccessGranted" { actionType = "GRANT_ACCESS" }
-	if event.EventName == "AccessRevoked" { actionType = "REVOKE_ACCESS" }
-
-	// For simple logic, we just use current owner as 'to_owner'. 'from_owner' would require previous state query, ignoring for sync simplicity or using DB trigger
-	_, err = db.Exec(historyQuery, event.TransactionID, asset.ID, actionType, "", asset.Owner, event.BlockNumber, asset.LastModifiedBy, event.Payload)
+// processPurgeEvent removes a purged asset from ASSETS. Its ASSET_HISTORY rows are kept
+// (the table has no foreign key on assets) and a final PURGE row records who purged it and why.
+func processPurgeEvent(db *sql.DB, event *client.ChaincodeEvent) {
+	var payload struct {
+		Asset  *Asset `json:"asset"`
+		Reason string `json:"reason"`
+		Actor  string `json:"actor"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.Asset == nil {
+		log.Printf("⚠️ Failed to parse purge payload: %v", err)
+		return
+	}
 
+	_, err := db.Exec(`
+		INSERT INTO asset_history (tx_id, asset_id, action_type, from_owner, block_number, timestamp, actor_id, asset_snapshot)
+		VALUES ($1, $2, 'PURGE', $3, $4, NOW(), $5, $6)
+	`, event.TransactionID, payload.Asset.ID, payload.Asset.Owner, event.BlockNumber, payload.Actor, event.Payload)
 	if err != nil {
 		log.Printf("❌ DB Error (Insert History): %v", err)
-	} else {
-		log.Printf("✅ Synced Asset %s to Postgres (Seq: %d)", asset.ID, asset.Sequence)
 	}
+
+	if _, err := db.Exec("DELETE FROM assets WHERE id = $1", payload.Asset.ID); err != nil {
+		log.Printf("❌ DB Error (Purge Asset): %v", err)
+		return
+	}
+	log.Printf("🗑️ Purged Asset %s from Postgres by %s: %s (history kept)", payload.Asset.ID, payload.Actor, payload.Reason)
 }
 
+// processDeleteEvent handles the legacy AssetDeleted event (payload: the asset ID); history is kept
 func processDeleteEvent(db *sql.DB, event *client.ChaincodeEvent) {
 	assetID := string(event.Payload)
-	
-	// Delete from Assets table
-	_, err := db.Exec("DELETE FROM assets WHERE id = $1", assetID)
-	if err != nil {
-		log.Printf("❌ DB Error (Delete Asset): %v", err)
-	}
 
-	// Add 'Old' record to history
-	_, err = db.Exec(`
+	_, err := db.Exec(`
 		INSERT INTO asset_history (tx_id, asset_id, action_type, block_number, timestamp)
 		VALUES ($1, $2, 'DELETE', $3, NOW())
 	`, event.TransactionID, assetID, event.BlockNumber)
-	
-	if err == nil {
-		log.Printf("🗑️ Deleted Asset %s from Postgres", assetID)
+	if err != nil {
+		log.Printf("❌ DB Error (Insert History): %v", err)
+	}
+
+	if _, err := db.Exec("DELETE FROM assets WHERE id = $1", assetID); err != nil {
+		log.Printf("❌ DB Error (Delete Asset): %v", err)
+		return
+	}
+	log.Printf("🗑️ Deleted Asset %s from Postgres (history kept)", assetID)
+}
+
+// processPrivateDetailsEvent records that an asset's private details changed.
+// The payload only carries the hash; the details themselves never leave the private data collection.
+func processPrivateDetailsEvent(db *sql.DB, event *client.ChaincodeEvent) {
+	var payload struct {
+		AssetID   string `json:"assetId"`
+		Hash      string `json:"hash"`
+		UpdatedBy string `json:"updatedBy"`
 	}
-	if err == nil {
-		log.Printf("🗑️ Deleted Asset %s from Postgres", assetID)
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		log.Printf("⚠️ Failed to parse private details payload: %v", err)
+		return
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO asset_history (tx_id, asset_id, action_type, block_number, timestamp, actor_id, asset_snapshot)
+		VALUES ($1, $2, 'PRIVATE_DETAILS', $3, NOW(), $4, $5)
+	`, event.TransactionID, payload.AssetID, event.BlockNumber, payload.UpdatedBy, event.Payload)
+
+	if err != nil {
+		log.Printf("❌ DB Error (Insert History): %v", err)
+	} else {
+		log.Printf("🔒 Private details of asset %s updated (hash %s)", payload.AssetID, payload.Hash)
 	}
 }
 
+// transferEventPayload is a pending transfer plus, when the transfer changed it
+// (lock on initiate, release on reject/cancel/expire/invalidate, new owner on execute), the asset
+type transferEventPayload struct {
+	PendingTransfer
+	Asset    *Asset   `json:"asset"`
+	Children []*Asset `json:"children"` // Bundled assets locked, released or moved with the parent
+	Payment  *TokenMovement `json:"payment"` // Settlement of a priced transfer, on execution only
+}
+
 func processTransferEvent(db *sql.DB, event *client.ChaincodeEvent) {
-	var pt PendingTransfer
-	if err := json.Unmarshal(event.Payload, &pt); err != nil {
+	var payload transferEventPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
 		log.Printf("⚠️ Failed to parse PendingTransfer payload: %v", err)
 		return
 	}
 
-	// We only log history for these events. The actual asset update happens via AssetTransferred event (for Executed)
-	// OR via separate AssetUpdated event if implemented that way.
-	// But Wait! The chaincode emits AssetTransferred AND TransferExecuted.
-	// We should avoid duplicate history.
-	
-	// If event is TransferExecuted, the AssetTransferred event (which carries the full Asset) 
-	// will handle updating the 'assets' table and logging the 'TRANSFER' action.
-	// So we might only want to log 'INITIATE_TRANSFER', 'APPROVE_TRANSFER', 'REJECT_TRANSFER' here.
-	
-	if event.EventName == "TransferExecuted" {
-		// We skip this because AssetTransferred should handle the main history record?
-		// Actually, TransferExecuted contains the specific multi-sig details.
-		// Let's log it anyway as "EXECUTE_TRANSFER"
+	recordTransfer(db, event, event.EventName, &payload, event.Payload)
+}
+
+// processTransfersExpiredEvent handles the batch emitted by the expiry sweeper (ExpirePendingTransfers)
+func processTransfersExpiredEvent(db *sql.DB, event *client.ChaincodeEvent) {
+	var batch struct {
+		Transfers []json.RawMessage `json:"transfers"`
+	}
+	if err := json.Unmarshal(event.Payload, &batch); err != nil {
+		log.Printf("⚠️ Failed to parse TransfersExpired payload: %v", err)
+		return
 	}
 
-	actionType := strings.ToUpper(strings.Replace(event.EventName, "Transfer", "", 1))
-	if event.EventName == "TransferInitiated" { actionType = "INITIATE_TRANSFER" }
+	for _, raw := range batch.Transfers {
+		var payload transferEventPayload
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			log.Printf("⚠️ Failed to parse expired transfer: %v", err)
+			continue
+		}
+		recordTransfer(db, event, "TransferExpired", &payload, raw)
+	}
+	log.Printf("⌛ Synced %d expired transfer(s)", len(batch.Transfers))
+}
+
+// recordTransfer syncs one transfer event into the ASSETS, PENDING_TRANSFERS and ASSET_HISTORY tables
+func recordTransfer(db *sql.DB, event *client.ChaincodeEvent, eventName string, payload *transferEventPayload, snapshot []byte) {
+	pt := payload.PendingTransfer
+
+	// Fabric delivers a single event per transaction, so the asset state has to be synced from here
+	if payload.Asset != nil {
+		upsertAsset(db, payload.Asset, event.TransactionID)
+	}
+
+	syncPendingTransfer(db, &pt)
+
+	if payload.Payment != nil {
+		recordTokenMovement(db, event, payload.Payment)
+	}
+
+	actionType := strings.ToUpper(strings.Replace(eventName, "Transfer", "", 1))
+	if eventName == "TransferInitiated" { actionType = "INITIATE_TRANSFER" }
+	if eventName == "TransferExecuted" { actionType = "TRANSFER" }
 	
 	// Insert into ASSET_HISTORY
 	historyQuery := `
 		INSERT INTO asset_history (tx_id, asset_id, action_type, from_owner, to_owner, block_number, timestamp, actor_id, asset_snapshot)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), 'Chaincode', $7)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), $7, $8)
 	`
-	// We don't easily know who the 'actor' is from the event payload without complex parsing
-	// So we set actor_id = 'Chaincode' or 'MultiSig'
-	// We have lastModifiedBy so why it hard to set actor_id = lastModifiedBy?
-	_, err := db.Exec(historyQuery, event.TransactionID, pt.AssetID, actionType, pt.CurrentOwner, pt.NewOwner, event.BlockNumber, event.Payload)
+	// The asset's lastModifiedBy is the signer that triggered the change; approvals without an asset fall back to 'Chaincode'
+	actorID := "Chaincode"
+	if payload.Asset != nil && payload.Asset.LastModifiedBy != "" {
+		actorID = payload.Asset.LastModifiedBy
+	}
+	_, err := db.Exec(historyQuery, event.TransactionID, pt.AssetID, actionType, pt.CurrentOwner, pt.NewOwner, event.BlockNumber, actorID, snapshot)
 
 	if err != nil {
 		log.Printf("❌ DB Error (Transfer History): %v", err)
 	} else {
 		log.Printf("✅ Logged Transfer Event %s for Asset %s", actionType, pt.AssetID)
 	}
+
+	// Bundled assets share the parent's fate; give each its own history row
+	for _, child := range payload.Children {
+		if !upsertAsset(db, child, event.TransactionID) {
+			continue
+		}
+		childSnapshot, _ := json.Marshal(child)
+		if _, err := db.Exec(historyQuery, event.TransactionID, child.ID, actionType, pt.CurrentOwner, pt.NewOwner, event.BlockNumber, actorID, childSnapshot); err != nil {
+			log.Printf("❌ DB Error (Transfer History): %v", err)
+		}
+	}
+}
+
+// syncPendingTransfer mirrors a transfer into the PENDING_TRANSFERS table.
+// A transfer is identified by its asset and creation time, since an asset can be transferred many times.
+func syncPendingTransfer(db *sql.DB, pt *PendingTransfer) {
+	var executedAt interface{}
+	if pt.ExecutedAt > 0 {
+		executedAt = pt.ExecutedAt
+	}
+	reason := pt.RejectionReason
+	if reason == "" {
+		reason = pt.CancellationReason
+	}
+
+	res, err := db.Exec(`
+		UPDATE pending_transfers
+		SET status = $3, executed_at = to_timestamp($4), rejection_reason = NULLIF($5, ''), expires_at = to_timestamp($6)
+		WHERE asset_id = $1 AND created_at = to_timestamp($2)
+	`, pt.AssetID, pt.CreatedAt, pt.Status, executedAt, reason, pt.ExpiresAt)
+	if err != nil {
+		log.Printf("❌ DB Error (Update Pending Transfer): %v", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO pending_transfers (asset_id, asset_name, current_owner, new_owner, status, created_at, expires_at, executed_at, rejection_reason, price)
+		VALUES ($1, $2, $3, $4, $5, to_timestamp($6), to_timestamp($7), to_timestamp($8), NULLIF($9, ''), $10)
+	`, pt.AssetID, pt.AssetName, pt.CurrentOwner, pt.NewOwner, pt.Status, pt.CreatedAt, pt.ExpiresAt, executedAt, reason, pt.Price)
+	if err != nil {
+		log.Printf("❌ DB Error (Insert Pending Transfer): %v", err)
+	}
+}
+
+func processTokenEvent(db *sql.DB, event *client.ChaincodeEvent) {
+	var movement TokenMovement
+	if err := json.Unmarshal(event.Payload, &movement); err != nil {
+		log.Printf("⚠️ Failed to parse token movement payload: %v", err)
+		return
+	}
+	recordTokenMovement(db, event, &movement)
+}
+
+// recordTokenMovement appends a movement to TOKEN_MOVEMENTS and mirrors the resulting balances
+// into TOKEN_BALANCES. A transaction moves tokens at most once, so a replayed event is skipped.
+func recordTokenMovement(db *sql.DB, event *client.ChaincodeEvent, m *TokenMovement) {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("❌ DB Error (Begin): %v", err)
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		INSERT INTO token_movements (tx_id, kind, from_account, to_account, amount, asset_id, actor_id, block_number, timestamp)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, NULLIF($6, ''), $7, $8, to_timestamp($9))
+		ON CONFLICT (tx_id) DO NOTHING
+	`, event.TransactionID, m.Kind, m.From, m.To, m.Amount, m.AssetID, m.Actor, event.BlockNumber, m.Timestamp)
+	if err != nil {
+		log.Printf("❌ DB Error (Insert Token Movement): %v", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		log.Printf("⏭️ Token movement of tx %s already synced", event.TransactionID)
+		return
+	}
+
+	balanceQuery := `
+		INSERT INTO token_balances (account, balance, updated_at)
+		VALUES ($1, $2, to_timestamp($3))
+		ON CONFLICT (account) DO UPDATE SET balance = EXCLUDED.balance, updated_at = EXCLUDED.updated_at
+	`
+	if m.From != "" {
+		if _, err := tx.Exec(balanceQuery, m.From, m.FromBalance, m.Timestamp); err != nil {
+			log.Printf("❌ DB Error (Upsert Token Balance): %v", err)
+			return
+		}
+	}
+	if _, err := tx.Exec(balanceQuery, m.To, m.ToBalance, m.Timestamp); err != nil {
+		log.Printf("❌ DB Error (Upsert Token Balance): %v", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("❌ DB Error (Commit): %v", err)
+		return
+	}
+	log.Printf("🪙 %s of %d tokens: %s -> %s", m.Kind, m.Amount, m.From, m.To)
 }
 
 // ConnectPostgres helper
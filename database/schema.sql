@@ -27,24 +27,101 @@ CREATE TABLE IF NOT EXISTS assets (
     metadata_url    TEXT,
     metadata_hash   CHAR(64),
     viewers         JSONB DEFAULT '[]',     -- Stores list of viewer IDs as JSON Array
+    viewer_expiry   JSONB DEFAULT '{}',     -- Time-limited grants: {"viewerId": expiresAt unix seconds}
     last_tx_id      VARCHAR(64),            -- usage to link back to Fabric Transaction
     last_modified_by VARCHAR(255),           -- Provenance: Who modified it last
     updated_at      TIMESTAMP,              -- Timestamp from Fabric Block
-    sequence        BIGINT DEFAULT 0        -- Synced from Chain for consistency
+    sequence        BIGINT DEFAULT 0,       -- Synced from Chain for consistency
+    total_units     BIGINT DEFAULT 0,       -- Fractional assets: fixed number of shares (0 = whole asset)
+    shares          JSONB DEFAULT '{}',     -- Fractional assets: {"holderId": units}
+    parent_id       VARCHAR(64),            -- Bundle parent this asset is attached to (NULL = standalone/root)
+    custodian       VARCHAR(64),            -- Borrower while the asset is on loan (NULL = with the owner)
+    custody_until   TIMESTAMP,              -- Loan due date
+    archived_at     TIMESTAMP,              -- Set while the asset is archived (status 'Archived')
+    archived_by     VARCHAR(64),
+    archive_reason  TEXT,
+    attributes      JSONB DEFAULT '{}',     -- Custom attributes declared by the asset type: {"name": value}
+    frozen_case     VARCHAR(100),           -- Case reference of the active legal freeze (NULL = not frozen)
+    frozen_at       TIMESTAMP,
+    frozen_by       VARCHAR(64)
 );
 
+-- Upgrade existing databases created before fractional ownership, bundles, lending, archiving, attributes, freezes and grant expiry
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS total_units BIGINT DEFAULT 0;
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS shares JSONB DEFAULT '{}';
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS parent_id VARCHAR(64);
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS custodian VARCHAR(64);
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS custody_until TIMESTAMP;
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS archived_by VARCHAR(64);
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS archive_reason TEXT;
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS attributes JSONB DEFAULT '{}';
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS frozen_case VARCHAR(100);
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS frozen_at TIMESTAMP;
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS frozen_by VARCHAR(64);
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS viewer_expiry JSONB DEFAULT '{}';
+
 -- Indexes for Explorer Performance
 CREATE INDEX idx_assets_owner ON assets(owner);
 CREATE INDEX idx_assets_type ON assets(asset_type);
 CREATE INDEX idx_assets_status ON assets(status);
 CREATE INDEX idx_assets_viewers ON assets USING gin (viewers); -- GIN index for JSONB Array searching
+CREATE INDEX IF NOT EXISTS idx_assets_parent ON assets(parent_id);
+CREATE INDEX IF NOT EXISTS idx_assets_custodian ON assets(custodian);
+CREATE INDEX IF NOT EXISTS idx_assets_shares ON assets USING gin (shares); -- "Assets where X holds any share" (shares ? 'X')
+CREATE INDEX IF NOT EXISTS idx_assets_attributes ON assets USING gin (attributes); -- Attribute filters (attributes @> '{"color": "red"}')
+
+-- 2b. ASSET_TYPES Table (Asset type registry, synced from the ledger)
+-- attributes holds the declared attributes: [{"name", "dataType", "required", "description"}]
+CREATE TABLE IF NOT EXISTS asset_types (
+    name            VARCHAR(50) PRIMARY KEY,
+    description     TEXT,
+    attributes      JSONB DEFAULT '[]',
+    updated_at      TIMESTAMP,
+    updated_by      VARCHAR(64),
+    sequence        BIGINT DEFAULT 0
+);
+
+-- 2c. ASSET_ATTESTATIONS Table (Auditor attestations, synced from the ledger)
+-- One row per AttestAsset transaction; the attestation stays on the asset on-chain as well.
+CREATE TABLE IF NOT EXISTS asset_attestations (
+    id              VARCHAR(64) PRIMARY KEY,    -- Transaction ID of the attestation
+    asset_id        VARCHAR(64) NOT NULL,       -- No foreign key: attestations outlive purged assets
+    attestation_type VARCHAR(50) NOT NULL,
+    document_hash   CHAR(64) NOT NULL,          -- SHA-256 of the supporting document
+    auditor         VARCHAR(64) NOT NULL,
+    auditor_msp     VARCHAR(64),
+    certificate_hash CHAR(64),                  -- SHA-256 of the auditor's signing certificate
+    issued_at       TIMESTAMP NOT NULL,
+    valid_until     TIMESTAMP NOT NULL,
+    block_number    BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_attestations_asset ON asset_attestations(asset_id);
+CREATE INDEX IF NOT EXISTS idx_attestations_valid_until ON asset_attestations(valid_until);
+
+-- 2d. ASSET_FREEZES Table (Legal freeze history, synced from the ledger)
+-- One row per FreezeAsset / UnfreezeAsset; assets.frozen_case holds the active freeze.
+CREATE TABLE IF NOT EXISTS asset_freezes (
+    id              SERIAL PRIMARY KEY,
+    tx_id           VARCHAR(64) NOT NULL UNIQUE,
+    asset_id        VARCHAR(64) NOT NULL,       -- No foreign key: the record outlives purged assets
+    action          VARCHAR(10) NOT NULL,       -- FREEZE, UNFREEZE
+    case_ref        VARCHAR(100) NOT NULL,
+    reason          TEXT,
+    actor_id        VARCHAR(64),
+    timestamp       TIMESTAMP NOT NULL,
+    block_number    BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_freezes_asset ON asset_freezes(asset_id);
+CREATE INDEX IF NOT EXISTS idx_freezes_case ON asset_freezes(case_ref);
 
 -- 3. ASSET_HISTORY Table (Audit Trail)
 -- Stores a permanent record of every state change (Provenance).
+-- asset_id deliberately has no foreign key: history must outlive purged assets.
 CREATE TABLE IF NOT EXISTS asset_history (
     id              SERIAL PRIMARY KEY,
     tx_id           VARCHAR(64) NOT NULL,
-    asset_id        VARCHAR(64) REFERENCES assets(id) ON DELETE CASCADE,
+    asset_id        VARCHAR(64),
     action_type     VARCHAR(50), -- e.g. CREATE, UPDATE, TRANSFER, GRANT_ACCESS
     from_owner      VARCHAR(64),
     to_owner        VARCHAR(64),
@@ -54,9 +131,17 @@ CREATE TABLE IF NOT EXISTS asset_history (
     is_valid        BOOLEAN DEFAULT TRUE,
     
     -- Snapshot of data at that point in time (Optional, but good for "Time Travel" queries)
-    asset_snapshot  JSONB
+    asset_snapshot  JSONB,
+    details         JSONB        -- Justification of admin actions, e.g. a forced transfer's reason and document hash
 );
 
+-- Upgrade existing databases: the old ON DELETE CASCADE wiped the trail of deleted assets
+ALTER TABLE asset_history DROP CONSTRAINT IF EXISTS asset_history_asset_id_fkey;
+
+-- Upgrade existing databases created before forced transfers
+ALTER TABLE asset_history ADD COLUMN IF NOT EXISTS details JSONB;
+CREATE INDEX IF NOT EXISTS idx_asset_history_action_type ON asset_history(action_type);
+
 -- 3b. USER_HISTORY Table (User & Admin Audit Trail)
 -- Stores profile updates and status changes (Lock/Unlock)
 CREATE TABLE IF NOT EXISTS user_history (
@@ -76,6 +161,35 @@ CREATE INDEX idx_history_asset_id ON asset_history(asset_id);
 CREATE INDEX idx_history_tx_id ON asset_history(tx_id);
 CREATE INDEX idx_history_timestamp ON asset_history(timestamp DESC); -- For recent transaction queries
 
+-- 3c. GROUP_MEMBERS Table (Viewer Groups, synced from the ledger)
+-- Assets can be shared with "role:<Role>" or "group:<name>" as well as with user IDs.
+CREATE TABLE IF NOT EXISTS group_members (
+    group_id        VARCHAR(64) NOT NULL,
+    user_id         VARCHAR(64) NOT NULL,
+    PRIMARY KEY (group_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_group_members_user ON group_members(user_id);
+
+-- viewer_principals lists every viewer ID that grants a user access, exactly like the chaincode
+-- (GetViewerPrincipals): the user ID, "role:<Role>", "group:<name>" per group, and EVERYONE.
+-- Use it against the viewers JSONB array: WHERE viewers ?| viewer_principals('Brad')
+CREATE OR REPLACE FUNCTION viewer_principals(uid TEXT) RETURNS TEXT[] AS $$
+    SELECT ARRAY[uid]
+        || COALESCE((SELECT ARRAY['role:' || role] FROM users WHERE id = uid AND role IS NOT NULL), '{}')
+        || COALESCE((SELECT array_agg('group:' || group_id ORDER BY group_id) FROM group_members WHERE user_id = uid), '{}')
+        || ARRAY['EVERYONE'];
+$$ LANGUAGE SQL STABLE;
+
+-- active_viewers drops the viewers whose grant has expired (viewer_expiry), like the chaincode and
+-- the backend do, since expired grants stay listed until the grant sweeper removes them.
+-- Combine it with viewer_principals: WHERE active_viewers(viewers, viewer_expiry) && viewer_principals('Brad')
+CREATE OR REPLACE FUNCTION active_viewers(viewers JSONB, viewer_expiry JSONB) RETURNS TEXT[] AS $$
+    SELECT COALESCE(array_agg(v), '{}')
+    FROM jsonb_array_elements_text(viewers) AS v
+    WHERE COALESCE((viewer_expiry->>v)::BIGINT, 0) = 0
+       OR (viewer_expiry->>v)::BIGINT > EXTRACT(EPOCH FROM now());
+$$ LANGUAGE SQL STABLE;
+
 -- 4. PENDING_TRANSFERS Table (Multi-Signature Transfers)
 -- Stores transfer requests that require approval from both parties
 CREATE TABLE IF NOT EXISTS pending_transfers (
@@ -84,13 +198,17 @@ CREATE TABLE IF NOT EXISTS pending_transfers (
     asset_name      VARCHAR(255),
     current_owner   VARCHAR(64) NOT NULL,  -- Must approve (initiator)
     new_owner       VARCHAR(64) NOT NULL,  -- Must approve (recipient)
-    status          VARCHAR(20) DEFAULT 'PENDING', -- PENDING, APPROVED, REJECTED, EXPIRED, EXECUTED
+    status          VARCHAR(20) DEFAULT 'PENDING', -- PENDING, FROZEN, APPROVED, REJECTED, EXPIRED, EXECUTED
     created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     expires_at      TIMESTAMP DEFAULT (CURRENT_TIMESTAMP + INTERVAL '24 hours'),
     executed_at     TIMESTAMP,
-    rejection_reason TEXT
+    rejection_reason TEXT,
+    price           BIGINT DEFAULT 0       -- Tokens the new owner pays on execution (0 = no payment)
 );
 
+-- Upgrade existing databases created before priced (delivery-versus-payment) transfers
+ALTER TABLE pending_transfers ADD COLUMN IF NOT EXISTS price BIGINT DEFAULT 0;
+
 -- 5. TRANSFER_SIGNATURES Table (Approval Records)
 -- Tracks who has approved each pending transfer
 CREATE TABLE IF NOT EXISTS transfer_signatures (
@@ -110,3 +228,27 @@ CREATE INDEX idx_pending_transfers_new_owner ON pending_transfers(new_owner);
 CREATE INDEX idx_pending_transfers_status ON pending_transfers(status);
 CREATE INDEX idx_pending_transfers_expires_at ON pending_transfers(expires_at);
 CREATE INDEX idx_transfer_signatures_pending_id ON transfer_signatures(pending_transfer_id);
+
+-- 6. TOKEN LEDGER (synced from the chaincode token ledger)
+-- Balances mirror the ledger after each movement; movements are the append-only journal
+-- of mints, user-to-user payments and settlements of priced transfers.
+CREATE TABLE IF NOT EXISTS token_balances (
+    account         VARCHAR(64) PRIMARY KEY,
+    balance         BIGINT NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS token_movements (
+    id              SERIAL PRIMARY KEY,
+    tx_id           VARCHAR(64) NOT NULL UNIQUE, -- A transaction moves tokens at most once
+    kind            VARCHAR(20) NOT NULL,        -- MINT, TRANSFER, SETTLEMENT
+    from_account    VARCHAR(64),                 -- NULL for mints
+    to_account      VARCHAR(64) NOT NULL,
+    amount          BIGINT NOT NULL,
+    asset_id        VARCHAR(64),                 -- Settlements: the asset paid for
+    actor_id        VARCHAR(64),
+    block_number    BIGINT,
+    timestamp       TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_token_movements_from ON token_movements(from_account);
+CREATE INDEX IF NOT EXISTS idx_token_movements_to ON token_movements(to_account);
@@ -20,6 +20,7 @@ export default function AssetCard({ asset, currentUser, onTransfer, onHistory, o
 
     const statusClass = statusColors[asset.status] || 'bg-slate-700 text-slate-300';
     const isOwner = asset.owner === currentUser.id;
+    const myUnits = asset.shares?.[currentUser.id] ?? 0;
 
     return (
         <div className="glass-panel rounded-xl p-5 hover:border-blue-500/30 transition-all duration-300 group">
@@ -33,9 +34,19 @@ export default function AssetCard({ asset, currentUser, onTransfer, onHistory, o
                         <span className="text-xs text-slate-400 font-mono tracking-wide">#{asset.ID}</span>
                     </div>
                 </div>
-                <span className={`px-2.5 py-1 rounded-full text-xs font-medium border ${statusClass} uppercase tracking-wider`}>
-                    {asset.status}
-                </span>
+                <div className="flex flex-col items-end gap-1">
+                    <span className={`px-2.5 py-1 rounded-full text-xs font-medium border ${statusClass} uppercase tracking-wider`}>
+                        {asset.status}
+                    </span>
+                    {asset.freeze && (
+                        <span
+                            className="px-2.5 py-1 rounded-full text-xs font-medium border bg-cyan-500/10 text-cyan-300 border-cyan-500/20 uppercase tracking-wider"
+                            title={asset.freeze.reason || undefined}
+                        >
+                            Frozen · {asset.freeze.caseRef}
+                        </span>
+                    )}
+                </div>
             </div>
 
             <div className="space-y-3 mb-5">
@@ -53,6 +64,69 @@ export default function AssetCard({ asset, currentUser, onTransfer, onHistory, o
                         {asset.owner} {isOwner && '(You)'}
                     </span>
                 </div>
+                {(asset.parentId || (asset.children && asset.children.length > 0)) && (
+                    <div className="flex items-center justify-between text-sm text-slate-400">
+                        <div className="flex items-center gap-2">
+                            <Tag size={14} /> <span>Bundle</span>
+                        </div>
+                        <span className="text-slate-200 font-mono text-xs">
+                            {asset.parentId ? `Attached to ${asset.parentId}` : `${asset.children!.length} attached`}
+                        </span>
+                    </div>
+                )}
+                {asset.custodian && (
+                    <div className="flex items-center justify-between text-sm text-slate-400">
+                        <div className="flex items-center gap-2">
+                            <UserIcon size={14} /> <span>On Loan</span>
+                        </div>
+                        <span className={`text-slate-200 ${asset.custodyUntil && asset.custodyUntil * 1000 < Date.now() ? 'text-red-400' : ''}`}>
+                            {asset.custodian === currentUser.id ? 'You' : asset.custodian}
+                            {asset.custodyUntil && ` · due ${new Date(asset.custodyUntil * 1000).toLocaleDateString()}`}
+                        </span>
+                    </div>
+                )}
+                {asset.archivedAt && (
+                    <div className="flex items-center justify-between text-sm text-slate-400">
+                        <div className="flex items-center gap-2">
+                            <Tag size={14} /> <span>Archived</span>
+                        </div>
+                        <span className="text-amber-300 text-xs" title={asset.archiveReason}>
+                            by {asset.archivedBy} · {new Date(asset.archivedAt * 1000).toLocaleDateString()}
+                        </span>
+                    </div>
+                )}
+                {!!asset.attestations?.length && (
+                    <div className="flex items-center justify-between text-sm text-slate-400">
+                        <div className="flex items-center gap-2">
+                            <Tag size={14} /> <span>Attested</span>
+                        </div>
+                        <span className="text-emerald-300 text-xs">
+                            {asset.attestations
+                                .filter(a => a.validUntil * 1000 >= Date.now())
+                                .map(a => a.type)
+                                .join(', ') || 'expired'}
+                        </span>
+                    </div>
+                )}
+                {asset.attributes && Object.entries(asset.attributes).map(([name, value]) => (
+                    <div key={name} className="flex items-center justify-between text-sm text-slate-400">
+                        <div className="flex items-center gap-2">
+                            <Tag size={14} /> <span>{name}</span>
+                        </div>
+                        <span className="text-slate-200 text-xs">{String(value)}</span>
+                    </div>
+                ))}
+                {!!asset.totalUnits && asset.shares && (
+                    <div className="flex items-center justify-between text-sm text-slate-400">
+                        <div className="flex items-center gap-2">
+                            <UserIcon size={14} /> <span>Shares</span>
+                        </div>
+                        <span className="text-slate-200">
+                            {Object.keys(asset.shares).length} holder(s)
+                            {myUnits > 0 && ` · You: ${myUnits}/${asset.totalUnits} (${((myUnits * 100) / asset.totalUnits).toFixed(1)}%)`}
+                        </span>
+                    </div>
+                )}
             </div>
 
             <div className="pt-4 border-t border-white/5 space-y-3">
@@ -1,7 +1,7 @@
 import { useState } from 'react';
 import { createAsset, uploadToIPFS } from '../services/api';
 import type { User } from '../types';
-import { X, Box, Tag, Link, Loader2, Save, Upload } from 'lucide-react';
+import { X, Box, Tag, Link, Loader2, Save, Upload, Lock } from 'lucide-react';
 
 interface CreateAssetModalProps {
     onClose: () => void;
@@ -21,6 +21,8 @@ export default function CreateAssetModal({ onClose, onSuccess, currentUser }: Cr
     });
 
     const [uploading, setUploading] = useState(false);
+    // Optional private details, stored in the private data collection instead of public state
+    const [privateData, setPrivateData] = useState({ purchasePrice: '', serialNumber: '' });
 
     const handleChange = (e: React.ChangeEvent<HTMLInputElement | HTMLSelectElement>) => {
         setFormData({ ...formData, [e.target.name]: e.target.value });
@@ -53,7 +55,10 @@ export default function CreateAssetModal({ onClose, onSuccess, currentUser }: Cr
                 owner: formData.owner,
                 status: formData.status,
                 metadata_url: formData.metadata_url
-            });
+            }, privateData.purchasePrice || privateData.serialNumber ? {
+                purchasePrice: privateData.purchasePrice ? Number(privateData.purchasePrice) : undefined,
+                serialNumber: privateData.serialNumber || undefined,
+            } : undefined);
             onSuccess();
             onClose();
         } catch (error) {
@@ -142,6 +147,25 @@ export default function CreateAssetModal({ onClose, onSuccess, currentUser }: Cr
                         <p className="text-xs text-slate-500 mt-1 ml-1">Enter external URL or upload to IPFS (Decentralized Storage).</p>
                     </div>
 
+                    <div>
+                        <label className="block text-sm font-medium text-slate-300 mb-1.5 ml-1 flex items-center gap-1.5">
+                            <Lock className="w-3.5 h-3.5 text-slate-500" /> Private Details (Optional)
+                        </label>
+                        <div className="grid grid-cols-2 gap-4">
+                            <input
+                                value={privateData.purchasePrice} onChange={(e) => setPrivateData({ ...privateData, purchasePrice: e.target.value })}
+                                type="number" min="0" step="any" placeholder="Purchase price"
+                                className="w-full bg-slate-900/50 border border-slate-700 rounded-lg py-2 px-4 text-white placeholder-slate-500 focus:outline-none focus:ring-2 focus:ring-blue-500/50 transition-all"
+                            />
+                            <input
+                                value={privateData.serialNumber} onChange={(e) => setPrivateData({ ...privateData, serialNumber: e.target.value })}
+                                type="text" placeholder="Serial number"
+                                className="w-full bg-slate-900/50 border border-slate-700 rounded-lg py-2 px-4 text-white placeholder-slate-500 focus:outline-none focus:ring-2 focus:ring-blue-500/50 transition-all"
+                            />
+                        </div>
+                        <p className="text-xs text-slate-500 mt-1 ml-1">Kept off the public ledger. Only you and users you share the asset with can read it.</p>
+                    </div>
+
                     <div className="pt-4 flex items-center justify-end gap-3">
                         <button
                             type="button"
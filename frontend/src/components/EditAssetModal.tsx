@@ -1,6 +1,6 @@
-import { useState } from 'react';
-import { updateAsset } from '../services/api';
-import type { Asset } from '../types';
+import { useState, useEffect } from 'react';
+import { updateAsset, getStatusRules } from '../services/api';
+import type { Asset, StatusRule } from '../types';
 import { X, Edit, Tag, Link, Loader2, Save, AlertCircle } from 'lucide-react';
 
 interface EditAssetModalProps {
@@ -16,6 +16,19 @@ export default function EditAssetModal({ asset, onClose, onSuccess }: EditAssetM
         status: asset.status,
         metadata_url: asset.metadata_url
     });
+    const [statusRules, setStatusRules] = useState<StatusRule[]>([]);
+
+    useEffect(() => {
+        getStatusRules().then(setStatusRules).catch((err) => console.error('Failed to load status rules', err));
+    }, []);
+
+    // Only offer the current status plus the transitions the chaincode allows from it
+    const currentRule = statusRules.find((rule) => rule.status === asset.status);
+    const statusOptions = currentRule
+        ? [asset.status, ...currentRule.allowedNext]
+        : statusRules.length > 0
+            ? [asset.status, ...statusRules.filter((rule) => !rule.systemManaged).map((rule) => rule.status).filter((status) => status !== asset.status)]
+            : [asset.status];
 
     const handleChange = (e: React.ChangeEvent<HTMLInputElement | HTMLSelectElement>) => {
         setFormData({ ...formData, [e.target.name]: e.target.value });
@@ -101,11 +114,9 @@ export default function EditAssetModal({ asset, onClose, onSuccess }: EditAssetM
                                 onChange={handleChange}
                                 className="w-full bg-slate-900/50 border border-slate-700 rounded-lg py-2 pl-10 pr-4 text-white focus:outline-none focus:ring-2 focus:ring-blue-500/50 transition-all appearance-none"
                             >
-                                <option value="Available">Available</option>
-                                <option value="Locked">Locked</option>
-                                <option value="Under Maintenance">Under Maintenance</option>
-                                <option value="Pending Transfer">Pending Transfer</option>
-                                <option value="Owned">Owned</option>
+                                {statusOptions.map((status) => (
+                                    <option key={status} value={status}>{status}</option>
+                                ))}
                             </select>
                         </div>
                     </div>
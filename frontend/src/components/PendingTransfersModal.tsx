@@ -1,5 +1,5 @@
 import { useState, useEffect } from 'react';
-import { getPendingTransfers, approveTransfer, rejectTransfer } from '../services/api';
+import { getPendingTransfers, approveTransfer, rejectTransfer, cancelTransfer } from '../services/api';
 import { X, Clock, CheckCircle2, XCircle, Package, User, ArrowRight, Loader2 } from 'lucide-react';
 
 interface PendingTransfer {
@@ -11,8 +11,12 @@ interface PendingTransfer {
     created_at: number; // Unix timestamp from blockchain
     expires_at: number; // Unix timestamp from blockchain
     approval_count: number;
+    required_approvals: number;
     has_signed: boolean;
     is_recipient: boolean;
+    is_approver: boolean; // Co-signer named by the transfer policy (e.g. escrow agent, auditor)
+    bundled_assets?: string[]; // Attached assets that move with this one
+    price?: number; // Tokens the recipient pays when the transfer executes
 }
 
 interface PendingTransfersModalProps {
@@ -50,6 +54,26 @@ export default function PendingTransfersModal({ onClose, onSuccess }: PendingTra
         } catch (error: unknown) {
             const err = error as { response?: { data?: { error?: string } } };
             alert(err.response?.data?.error || 'Approval failed');
+            // Expired or invalidated transfers are closed on-chain, so refresh the list
+            fetchPendingTransfers();
+        } finally {
+            setActionLoading(null);
+        }
+    };
+
+    const handleCancel = async (assetId: string) => {
+        const reason = prompt('Reason for cancelling (optional):');
+        if (reason === null) return; // User cancelled
+
+        setActionLoading(assetId);
+        try {
+            const result = await cancelTransfer(assetId, reason);
+            alert(result.message);
+            onSuccess();
+            fetchPendingTransfers();
+        } catch (error: unknown) {
+            const err = error as { response?: { data?: { error?: string } } };
+            alert(err.response?.data?.error || 'Cancellation failed');
         } finally {
             setActionLoading(null);
         }
@@ -123,6 +147,11 @@ export default function PendingTransfersModal({ onClose, onSuccess }: PendingTra
                                             <div>
                                                 <h3 className="font-semibold text-white">{transfer.asset_name}</h3>
                                                 <p className="text-xs text-slate-400 font-mono">{transfer.asset_id}</p>
+                                                {transfer.bundled_assets && transfer.bundled_assets.length > 0 && (
+                                                    <p className="text-xs text-slate-500 mt-0.5">
+                                                        + {transfer.bundled_assets.length} attached: <span className="font-mono">{transfer.bundled_assets.join(', ')}</span>
+                                                    </p>
+                                                )}
                                             </div>
                                         </div>
                                         <div className="text-right">
@@ -131,8 +160,13 @@ export default function PendingTransfersModal({ onClose, onSuccess }: PendingTra
                                                 {getTimeRemaining(transfer.expires_at)}
                                             </div>
                                             <div className="text-xs text-slate-500 mt-1">
-                                                {transfer.approval_count}/2 signatures
+                                                {transfer.approval_count}/{transfer.required_approvals} signatures
                                             </div>
+                                            {transfer.price ? (
+                                                <div className="text-xs text-emerald-400 font-semibold mt-1">
+                                                    🪙 {transfer.price} tokens{transfer.is_recipient ? ' (paid on approval)' : ''}
+                                                </div>
+                                            ) : null}
                                         </div>
                                     </div>
 
@@ -156,7 +190,7 @@ export default function PendingTransfersModal({ onClose, onSuccess }: PendingTra
                                     </div>
 
                                     {/* Action Buttons */}
-                                    {!transfer.has_signed && transfer.is_recipient && (
+                                    {!transfer.has_signed && (transfer.is_recipient || transfer.is_approver) && (
                                         <div className="flex gap-3">
                                             <button
                                                 onClick={() => handleApprove(transfer.asset_id)}
@@ -172,27 +206,47 @@ export default function PendingTransfersModal({ onClose, onSuccess }: PendingTra
                                                     </>
                                                 )}
                                             </button>
+                                            {transfer.is_recipient && (
+                                                <button
+                                                    onClick={() => handleReject(transfer.asset_id)}
+                                                    disabled={actionLoading === transfer.asset_id}
+                                                    className="flex-1 py-2.5 bg-red-600 hover:bg-red-500 text-white rounded-lg font-medium shadow-lg shadow-red-500/20 transition-all flex items-center justify-center gap-2 disabled:opacity-50 disabled:cursor-not-allowed"
+                                                >
+                                                    {actionLoading === transfer.asset_id ? (
+                                                        <Loader2 className="animate-spin w-4 h-4" />
+                                                    ) : (
+                                                        <>
+                                                            <XCircle className="w-4 h-4" />
+                                                            Reject
+                                                        </>
+                                                    )}
+                                                </button>
+                                            )}
+                                        </div>
+                                    )}
+
+                                    {transfer.has_signed && !transfer.is_recipient && !transfer.is_approver && (
+                                        <div className="flex items-center gap-3">
+                                            <div className="flex-1 text-center py-2 bg-blue-500/10 border border-blue-500/20 rounded-lg">
+                                                <p className="text-sm text-blue-300">
+                                                    ✓ You initiated this transfer. Awaiting remaining approvals.
+                                                </p>
+                                            </div>
                                             <button
-                                                onClick={() => handleReject(transfer.asset_id)}
+                                                onClick={() => handleCancel(transfer.asset_id)}
                                                 disabled={actionLoading === transfer.asset_id}
-                                                className="flex-1 py-2.5 bg-red-600 hover:bg-red-500 text-white rounded-lg font-medium shadow-lg shadow-red-500/20 transition-all flex items-center justify-center gap-2 disabled:opacity-50 disabled:cursor-not-allowed"
+                                                className="px-4 py-2 bg-slate-700 hover:bg-slate-600 text-white rounded-lg text-sm font-medium transition-all flex items-center gap-2 disabled:opacity-50 disabled:cursor-not-allowed"
                                             >
-                                                {actionLoading === transfer.asset_id ? (
-                                                    <Loader2 className="animate-spin w-4 h-4" />
-                                                ) : (
-                                                    <>
-                                                        <XCircle className="w-4 h-4" />
-                                                        Reject
-                                                    </>
-                                                )}
+                                                <XCircle className="w-4 h-4" />
+                                                Cancel
                                             </button>
                                         </div>
                                     )}
 
-                                    {transfer.has_signed && !transfer.is_recipient && (
-                                        <div className="text-center py-2 bg-blue-500/10 border border-blue-500/20 rounded-lg">
-                                            <p className="text-sm text-blue-300">
-                                                ✓ You initiated this transfer. Awaiting recipient approval.
+                                    {transfer.has_signed && transfer.is_approver && (
+                                        <div className="text-center py-2 bg-green-500/10 border border-green-500/20 rounded-lg">
+                                            <p className="text-sm text-green-300">
+                                                ✓ You have co-signed this transfer.
                                             </p>
                                         </div>
                                     )}
@@ -1,6 +1,7 @@
 import { useState } from 'react';
 import { grantAccess } from '../services/api';
-import { X, Eye, Users, Loader2, Globe } from 'lucide-react';
+import type { ViewerPermission } from '../types';
+import { X, Eye, Users, Loader2, Globe, Clock } from 'lucide-react';
 
 interface ShareModalProps {
     assetId: string;
@@ -10,13 +11,15 @@ interface ShareModalProps {
 
 export default function ShareModal({ assetId, onClose, onSuccess }: ShareModalProps) {
     const [viewerId, setViewerId] = useState('');
+    const [permission, setPermission] = useState<ViewerPermission>('full');
+    const [expiresInHours, setExpiresInHours] = useState('');
     const [loading, setLoading] = useState(false);
 
     const handleSubmit = async (e: React.FormEvent) => {
         e.preventDefault();
         setLoading(true);
         try {
-            await grantAccess(assetId, viewerId);
+            await grantAccess(assetId, viewerId, permission, expiresInHours ? Number(expiresInHours) : undefined);
             onSuccess();
             onClose();
         } catch (error) {
@@ -51,13 +54,39 @@ export default function ShareModal({ assetId, onClose, onSuccess }: ShareModalPr
                                 <Users className="absolute left-3 top-1/2 -translate-y-1/2 text-slate-500 w-5 h-5" />
                                 <input
                                     value={viewerId} onChange={(e) => setViewerId(e.target.value)}
-                                    type="text" required placeholder="User ID or 'EVERYONE'"
+                                    type="text" required placeholder="User ID, role:Auditor, group:name or 'EVERYONE'"
                                     className="w-full bg-slate-900/50 border border-slate-700 rounded-lg py-2.5 pl-10 pr-4 text-white placeholder-slate-500 focus:outline-none focus:ring-2 focus:ring-blue-500/50 transition-all"
                                 />
                             </div>
                             <p className="text-xs text-slate-500 mt-2 ml-1 flex items-center gap-1">
                                 <Globe size={12} /> Type <b>EVERYONE</b> for public access.
                             </p>
+                            <p className="text-xs text-slate-500 mt-1 ml-1 flex items-center gap-1">
+                                <Users size={12} /> Use <b>role:</b> or <b>group:</b> to share with a whole role or group.
+                            </p>
+                        </div>
+
+                        <div className="mb-4">
+                            <label className="block text-sm font-medium text-slate-300 mb-1.5 ml-1">Permission</label>
+                            <select
+                                value={permission} onChange={(e) => setPermission(e.target.value as ViewerPermission)}
+                                className="w-full bg-slate-900/50 border border-slate-700 rounded-lg py-2.5 px-4 text-white focus:outline-none focus:ring-2 focus:ring-blue-500/50 transition-all"
+                            >
+                                <option value="full">Full (including private details)</option>
+                                <option value="metadata">Metadata only</option>
+                            </select>
+                        </div>
+
+                        <div className="mb-6">
+                            <label className="block text-sm font-medium text-slate-300 mb-1.5 ml-1">Expires In (hours)</label>
+                            <div className="relative">
+                                <Clock className="absolute left-3 top-1/2 -translate-y-1/2 text-slate-500 w-5 h-5" />
+                                <input
+                                    value={expiresInHours} onChange={(e) => setExpiresInHours(e.target.value)}
+                                    type="number" min="0" step="any" placeholder="Never"
+                                    className="w-full bg-slate-900/50 border border-slate-700 rounded-lg py-2.5 pl-10 pr-4 text-white placeholder-slate-500 focus:outline-none focus:ring-2 focus:ring-blue-500/50 transition-all"
+                                />
+                            </div>
                         </div>
 
                         <button
@@ -11,14 +11,18 @@ interface TransferModalProps {
 
 export default function TransferModal({ assetId, currentOwner, onClose, onSuccess }: TransferModalProps) {
     const [newOwner, setNewOwner] = useState('');
+    const [expiresInHours, setExpiresInHours] = useState('');
+    const [price, setPrice] = useState('');
     const [loading, setLoading] = useState(false);
 
     const handleSubmit = async (e: React.FormEvent) => {
         e.preventDefault();
         setLoading(true);
         try {
-            const result = await initiateTransfer(assetId, newOwner);
-            alert(`Transfer initiated! Pending ID: ${result.pending_id}\n\nThe recipient (${newOwner}) must approve within 24 hours.`);
+            const result = await initiateTransfer(assetId, newOwner, Number(expiresInHours) || undefined, Number(price) || undefined);
+            const deadline = new Date(result.expires_at * 1000).toLocaleString();
+            const payment = result.price ? `\nThey pay ${result.price} tokens when the transfer executes.` : '';
+            alert(`Transfer initiated!\n\nThe recipient (${newOwner}) must approve before ${deadline}.${payment}`);
             onSuccess();
             onClose();
         } catch (error: unknown) {
@@ -50,7 +54,7 @@ export default function TransferModal({ assetId, currentOwner, onClose, onSucces
                             <div>
                                 <p className="text-sm font-semibold text-amber-300 mb-1">Multi-Signature Required</p>
                                 <p className="text-xs text-amber-200/80">
-                                    This transfer requires approval from both parties. The recipient has 24 hours to accept or reject.
+                                    This transfer requires approval from both parties. The recipient must accept or reject before the deadline.
                                 </p>
                             </div>
                         </div>
@@ -82,8 +86,32 @@ export default function TransferModal({ assetId, currentOwner, onClose, onSucces
                                     className="w-full bg-slate-900/50 border border-slate-700 rounded-lg py-2.5 pl-10 pr-4 text-white placeholder-slate-500 focus:outline-none focus:ring-2 focus:ring-blue-500/50 transition-all"
                                 />
                             </div>
+                        </div>
+
+                        <div className="mb-6">
+                            <label className="block text-sm font-medium text-slate-300 mb-1.5 ml-1">Approval Window (hours)</label>
+                            <div className="relative">
+                                <Clock className="absolute left-3 top-1/2 -translate-y-1/2 text-slate-500 w-5 h-5" />
+                                <input
+                                    value={expiresInHours} onChange={(e) => setExpiresInHours(e.target.value)}
+                                    type="number" min="0" step="any" placeholder="Default for this asset type"
+                                    className="w-full bg-slate-900/50 border border-slate-700 rounded-lg py-2.5 pl-10 pr-4 text-white placeholder-slate-500 focus:outline-none focus:ring-2 focus:ring-blue-500/50 transition-all"
+                                />
+                            </div>
+                            <p className="text-xs text-slate-500 mt-1.5 ml-1">
+                                ⏱️ Leave empty for the default (24h unless configured). Limits are set per asset type.
+                            </p>
+                        </div>
+
+                        <div className="mb-6">
+                            <label className="block text-sm font-medium text-slate-300 mb-1.5 ml-1">Price (tokens)</label>
+                            <input
+                                value={price} onChange={(e) => setPrice(e.target.value)}
+                                type="number" min="0" step="1" placeholder="Free transfer"
+                                className="w-full bg-slate-900/50 border border-slate-700 rounded-lg py-2.5 px-4 text-white placeholder-slate-500 focus:outline-none focus:ring-2 focus:ring-blue-500/50 transition-all"
+                            />
                             <p className="text-xs text-slate-500 mt-1.5 ml-1">
-                                ⏱️ Recipient must approve within 24 hours
+                                🪙 The recipient pays this from their token balance in the same transaction that hands over the asset.
                             </p>
                         </div>
 
@@ -1,5 +1,5 @@
 import { useEffect, useState } from 'react';
-import { Users, Database, ArrowRightLeft } from 'lucide-react';
+import { Users, Database, ArrowRightLeft, Gavel } from 'lucide-react';
 import { getDashboardStats } from '../../../services/api';
 import type { DashboardStats } from '../../../types';
 
@@ -25,7 +25,7 @@ export default function Analytics() {
     return (
         <div className="space-y-6">
             <h2 className="text-xl font-bold text-white">System Overview</h2>
-            <div className="grid grid-cols-1 md:grid-cols-3 gap-6">
+            <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-4 gap-6">
                 <div className="glass-panel p-6 rounded-xl relative overflow-hidden group hover:bg-slate-800/60 transition-colors">
                     <div className="absolute top-0 right-0 p-4 opacity-10 group-hover:opacity-20 transition-opacity">
                         <Users size={64} />
@@ -67,7 +67,59 @@ export default function Analytics() {
                     <div className="text-3xl font-bold text-white mb-1">{stats?.pending_transfers || 0}</div>
                     <div className="text-xs text-slate-500">Awaiting Approval</div>
                 </div>
+
+                <div className="glass-panel p-6 rounded-xl relative overflow-hidden group hover:bg-slate-800/60 transition-colors">
+                    <div className="absolute top-0 right-0 p-4 opacity-10 group-hover:opacity-20 transition-opacity">
+                        <Gavel size={64} />
+                    </div>
+                    <div className="flex items-center gap-3 mb-2">
+                        <div className="p-2 bg-rose-500/20 rounded-lg text-rose-400">
+                            <Gavel size={20} />
+                        </div>
+                        <span className="text-slate-400 font-medium">Forced Transfers</span>
+                    </div>
+                    <div className="text-3xl font-bold text-white mb-1">{stats?.force_transfers || 0}</div>
+                    <div className="text-xs text-slate-500">Admin Overrides</div>
+                </div>
             </div>
+
+            {stats?.recent_force_transfers && stats.recent_force_transfers.length > 0 && (
+                <div className="glass-panel rounded-xl overflow-hidden">
+                    <div className="p-6 border-b border-slate-700/50">
+                        <h3 className="text-lg font-semibold text-white flex items-center gap-2">
+                            <Gavel size={20} className="text-rose-400" />
+                            Recent Forced Transfers
+                        </h3>
+                    </div>
+                    <div className="overflow-x-auto">
+                        <table className="w-full text-left text-sm">
+                            <thead className="bg-slate-900/50 text-slate-400 uppercase text-xs font-semibold">
+                                <tr>
+                                    <th className="px-6 py-4">Asset</th>
+                                    <th className="px-6 py-4">From → To</th>
+                                    <th className="px-6 py-4">Admin</th>
+                                    <th className="px-6 py-4">Reason</th>
+                                    <th className="px-6 py-4 text-right">When</th>
+                                </tr>
+                            </thead>
+                            <tbody className="divide-y divide-slate-700/50">
+                                {stats.recent_force_transfers.map((ft) => (
+                                    <tr key={ft.tx_id} className="hover:bg-slate-800/30 transition-colors">
+                                        <td className="px-6 py-4 font-mono text-white">{ft.asset_id}</td>
+                                        <td className="px-6 py-4 text-slate-300">{ft.from_owner} → {ft.to_owner}</td>
+                                        <td className="px-6 py-4 text-slate-300">@{ft.actor_id}</td>
+                                        <td className="px-6 py-4">
+                                            <div className="text-slate-300">{ft.reason}</div>
+                                            <div className="text-xs text-slate-500 font-mono" title={ft.document_hash}>doc {ft.document_hash.slice(0, 12)}…</div>
+                                        </td>
+                                        <td className="px-6 py-4 text-right text-slate-400">{new Date(ft.timestamp).toLocaleString()}</td>
+                                    </tr>
+                                ))}
+                            </tbody>
+                        </table>
+                    </div>
+                </div>
+            )}
         </div>
     );
 }
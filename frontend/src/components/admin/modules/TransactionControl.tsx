@@ -1,20 +1,37 @@
 import { useEffect, useState } from 'react';
-import { ArrowRightLeft, Clock } from 'lucide-react';
+import { ArrowRightLeft, Clock, Ban } from 'lucide-react';
 
-import { getPendingTransfers } from '../../../services/api';
+import { getPendingTransfers, adminCancelTransfer } from '../../../services/api';
 import type { PendingTransfer } from '../../../types';
 
 export default function TransactionControl() {
     const [transfers, setTransfers] = useState<PendingTransfer[]>([]);
     const [loading, setLoading] = useState(true);
 
-    useEffect(() => {
+    const loadTransfers = () => {
         getPendingTransfers()
             .then(setTransfers)
             .catch(console.error)
             .finally(() => setLoading(false));
+    };
+
+    useEffect(() => {
+        loadTransfers();
     }, []);
 
+    const handleCancel = async (assetId: string) => {
+        const reason = prompt('Reason for cancelling this transfer (recorded on-chain):');
+        if (!reason) return;
+
+        try {
+            await adminCancelTransfer(assetId, reason);
+            loadTransfers();
+        } catch (error: unknown) {
+            const err = error as { response?: { data?: { error?: string } } };
+            alert(err.response?.data?.error || 'Cancellation failed');
+        }
+    };
+
     if (loading) return <div>Loading transactions...</div>;
 
     return (
@@ -39,6 +56,7 @@ export default function TransactionControl() {
                                     <th className="px-6 py-4">To</th>
                                     <th className="px-6 py-4">Status</th>
                                     <th className="px-6 py-4">Created At</th>
+                                    <th className="px-6 py-4">Actions</th>
                                 </tr>
                             </thead>
                             <tbody className="divide-y divide-slate-700/50">
@@ -56,6 +74,14 @@ export default function TransactionControl() {
                                         <td className="px-6 py-4 text-slate-400">
                                             {new Date(tx.created_at).toLocaleString()}
                                         </td>
+                                        <td className="px-6 py-4">
+                                            <button
+                                                onClick={() => handleCancel(tx.asset_id)}
+                                                className="px-3 py-1 bg-red-500/10 text-red-400 border border-red-500/20 rounded-lg text-xs font-medium flex items-center gap-1 hover:bg-red-500/20 transition-colors"
+                                            >
+                                                <Ban size={12} /> Cancel
+                                            </button>
+                                        </td>
                                     </tr>
                                 ))}
                             </tbody>
@@ -127,7 +127,9 @@ export default function ExplorerView({
                             type: asset.type,
                             owner: asset.owner,
                             status: asset.status,
-                            metadata_url: asset.metadata_url
+                            metadata_url: asset.metadata_url,
+                            attributes: asset.attributes,
+                            freeze: asset.frozen ? { action: 'FREEZE', caseRef: asset.frozen_case ?? '', reason: '', actor: '', timestamp: 0, txId: '' } : undefined,
                         };
                         return (
                             <AssetCard
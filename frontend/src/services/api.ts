@@ -1,5 +1,5 @@
 import axios from 'axios';
-import type { Asset, User, AssetHistory, DashboardStats, UserStats } from '../types';
+import type { Asset, User, AssetHistory, DashboardStats, UserStats, StatusRule, TransferPolicy, Page, AssetPrivateDetails, Holding, AssetTreeNode, ImportResult, ViewerPermission, ViewerGroup, TokenBalance, TokenMovement, AssetTypeDef, AttributeDef, AttributeValue, Attestation, FreezeRecord } from '../types';
 
 const api = axios.create({
     baseURL: '/api',
@@ -32,29 +32,132 @@ api.interceptors.request.use((config) => {
 //     return response.data;
 // };
 // Updated to accept filters
-export const getAssets = async (userId?: string, role?: string): Promise<Asset[]> => {
+export const getAssetsPage = async (userId?: string, role?: string, bookmark?: string, pageSize?: number): Promise<Page<Asset>> => {
     const params = new URLSearchParams();
     if (userId) params.append('user_id', userId);
     if (role) params.append('user_role', role);
+    if (bookmark) params.append('bookmark', bookmark);
+    if (pageSize) params.append('page_size', String(pageSize));
 
-    const response = await api.get<Asset[]>(`/assets?${params.toString()}`);
+    const response = await api.get<Page<Asset>>(`/assets?${params.toString()}`);
     return response.data;
 };
 
-export const grantAccess = async (id: string, viewerId: string) => {
-    const response = await api.post(`/protected/assets/${id}/access`, { viewer_id: viewerId });
+// Follows the bookmark through every page
+export const getAssets = async (userId?: string, role?: string): Promise<Asset[]> => {
+    const assets: Asset[] = [];
+    let bookmark: string | undefined;
+    do {
+        const page = await getAssetsPage(userId, role, bookmark);
+        assets.push(...page.records);
+        bookmark = page.has_more ? page.bookmark : undefined;
+    } while (bookmark);
+    return assets;
+};
+
+export const grantAccess = async (id: string, viewerId: string, permission?: ViewerPermission, expiresInHours?: number) => {
+    const response = await api.post(`/protected/assets/${id}/access`, {
+        viewer_id: viewerId,
+        permission,
+        expires_in_hours: expiresInHours,
+    });
     return response.data;
 };
 
-export const createAsset = async (asset: Partial<Asset>) => {
+export const createAsset = async (asset: Partial<Asset>, privateDetails?: AssetPrivateDetails) => {
     const response = await api.post('/protected/assets', { // Use Protected Route
         ...asset,
         id: asset.ID,
         metadata_url: asset.metadata_url,
+        private_details: privateDetails,
     });
     return response.data;
 };
 
+// Bulk import from a .csv or .json file; all rows are created or none.
+// A rejected batch (422) still resolves so the per-row results can be shown.
+export const importAssets = async (file: File): Promise<ImportResult> => {
+    const formData = new FormData();
+    formData.append('file', file);
+    const response = await api.post<ImportResult>('/protected/assets/import', formData, {
+        headers: {
+            'Content-Type': 'multipart/form-data',
+        },
+        validateStatus: (status) => status < 300 || status === 422,
+    });
+    return response.data;
+};
+
+export const lendAsset = async (id: string, borrower: string, until: number) => {
+    const response = await api.post(`/protected/assets/${id}/lend`, { borrower, until });
+    return response.data;
+};
+
+export const returnAsset = async (id: string) => {
+    const response = await api.post(`/protected/assets/${id}/return`);
+    return response.data;
+};
+
+export const recallAsset = async (id: string) => {
+    const response = await api.post(`/protected/assets/${id}/recall`);
+    return response.data;
+};
+
+export const getOverdueLoans = async (): Promise<Asset[]> => {
+    const response = await api.get<Asset[]>('/loans/overdue');
+    return response.data || [];
+};
+
+export const fractionalizeAsset = async (id: string, totalUnits: number) => {
+    const response = await api.post(`/protected/assets/${id}/fractionalize`, { total_units: totalUnits });
+    return response.data;
+};
+
+export const transferShares = async (id: string, recipient: string, units: number) => {
+    const response = await api.post(`/protected/assets/${id}/shares/transfer`, { recipient, units });
+    return response.data;
+};
+
+export const getAssetHoldings = async (id: string): Promise<Holding[]> => {
+    const response = await api.get<Holding[]>(`/assets/${id}/holdings`);
+    return response.data;
+};
+
+export const attachAsset = async (parentId: string, childId: string) => {
+    const response = await api.post(`/protected/assets/${parentId}/children`, { child_id: childId });
+    return response.data;
+};
+
+export const detachAsset = async (parentId: string, childId: string) => {
+    const response = await api.delete(`/protected/assets/${parentId}/children/${childId}`);
+    return response.data;
+};
+
+export const getAssetTree = async (id: string): Promise<AssetTreeNode> => {
+    const response = await api.get<AssetTreeNode>(`/assets/${id}/tree`);
+    return response.data;
+};
+
+export const setAssetPrivateDetails = async (id: string, details: AssetPrivateDetails) => {
+    const response = await api.put(`/protected/assets/${id}/private`, details);
+    return response.data;
+};
+
+export const getAssetPrivateDetails = async (id: string): Promise<AssetPrivateDetails> => {
+    const response = await api.get<AssetPrivateDetails>(`/protected/assets/${id}/private`);
+    return response.data;
+};
+
+export const getAssetPrivateDetailsHash = async (id: string): Promise<{ asset_id: string; hash: string }> => {
+    const response = await api.get(`/assets/${id}/private-hash`);
+    return response.data;
+};
+
+export const verifyAssetPrivateDetails = async (id: string, details: AssetPrivateDetails): Promise<{ asset_id: string; valid: boolean }> => {
+    const response = await api.post(`/assets/${id}/private-hash/verify`, details);
+    return response.data;
+};
+
 export const uploadToIPFS = async (file: File) => {
     const formData = new FormData();
     formData.append('file', file);
@@ -105,19 +208,37 @@ export const getAssetHistory = async (id: string): Promise<AssetHistory[]> => {
 };
 
 // Multi-Signature Transfer Functions
-export const initiateTransfer = async (assetId: string, newOwner: string) => {
+export const initiateTransfer = async (assetId: string, newOwner: string, expiresInHours?: number, price?: number) => {
     const response = await api.post('/protected/transfers/initiate', {
         asset_id: assetId,
-        new_owner: newOwner
+        new_owner: newOwner,
+        expires_in_hours: expiresInHours || 0, // 0 = asset type default
+        price: price || 0 // Tokens the recipient pays on execution, 0 = free
     });
     return response.data;
 };
 
-export const getPendingTransfers = async () => {
-    const response = await api.get('/protected/transfers/pending');
+export const getPendingTransfersPage = async (bookmark?: string, pageSize?: number) => {
+    const params = new URLSearchParams();
+    if (bookmark) params.append('bookmark', bookmark);
+    if (pageSize) params.append('page_size', String(pageSize));
+
+    const response = await api.get(`/protected/transfers/pending?${params.toString()}`);
     return response.data;
 };
 
+// Follows the bookmark through every page
+export const getPendingTransfers = async () => {
+    const transfers = [];
+    let bookmark: string | undefined;
+    do {
+        const page = await getPendingTransfersPage(bookmark);
+        transfers.push(...page.records);
+        bookmark = page.has_more ? page.bookmark : undefined;
+    } while (bookmark);
+    return transfers;
+};
+
 export const approveTransfer = async (assetId: string) => {
     const response = await api.post(`/protected/transfers/${assetId}/approve`);
     return response.data;
@@ -128,18 +249,29 @@ export const rejectTransfer = async (assetId: string, reason: string) => {
     return response.data;
 };
 
-export const updateAsset = async (id: string, updates: { name: string; status: string; metadata_url: string }) => {
+export const cancelTransfer = async (assetId: string, reason: string) => {
+    const response = await api.post(`/protected/transfers/${assetId}/cancel`, { reason });
+    return response.data;
+};
+
+export const updateAsset = async (id: string, updates: { name: string; status: string; metadata_url: string; attributes?: Record<string, AttributeValue> }) => {
     const response = await api.put(`/protected/assets/${id}`, updates);
     return response.data;
 };
 
+export const getStatusRules = async (): Promise<StatusRule[]> => {
+    const response = await api.get<StatusRule[]>('/assets/status-rules');
+    return response.data;
+};
+
 
 // --- Explorer API (Postgres) ---
-export const searchAssets = async (query: string, owner?: string, type?: string): Promise<Asset[]> => {
+export const searchAssets = async (query: string, owner?: string, type?: string, holder?: string): Promise<Asset[]> => {
     const params = new URLSearchParams();
     if (query) params.append('search', query);
     if (owner) params.append('owner', owner);
     if (type) params.append('type', type);
+    if (holder) params.append('holder', holder);
 
     const response = await api.get<Asset[]>(`/explorer/assets?${params.toString()}`);
     return response.data;
@@ -176,3 +308,125 @@ export const setUserStatus = async (userId: string, status: 'Active' | 'Locked')
     const response = await api.post(`/protected/admin/users/${userId}/status`, { status });
     return response.data;
 };
+
+export const getTransferPolicies = async (): Promise<TransferPolicy[]> => {
+    const response = await api.get<TransferPolicy[]>('/protected/admin/policies');
+    return response.data;
+};
+
+export const setTransferPolicy = async (scope: 'type' | 'asset', scopeId: string, policy: { threshold: number; approverRoles: string[]; approverUsers: string[] }) => {
+    const response = await api.put(`/protected/admin/policies/${scope}/${encodeURIComponent(scopeId)}`, policy);
+    return response.data;
+};
+
+export const deleteTransferPolicy = async (scope: 'type' | 'asset', scopeId: string) => {
+    const response = await api.delete(`/protected/admin/policies/${scope}/${encodeURIComponent(scopeId)}`);
+    return response.data;
+};
+
+export const adminCancelTransfer = async (assetId: string, reason: string) => {
+    const response = await api.post(`/protected/admin/transfers/${assetId}/cancel`, { reason });
+    return response.data;
+};
+
+export const getViewerGroups = async (): Promise<ViewerGroup[]> => {
+    const response = await api.get<ViewerGroup[]>('/protected/admin/groups');
+    return response.data || [];
+};
+
+export const setViewerGroupMembers = async (groupId: string, members: string[]) => {
+    const response = await api.put(`/protected/admin/groups/${encodeURIComponent(groupId)}`, { members });
+    return response.data;
+};
+
+export const deleteViewerGroup = async (groupId: string) => {
+    const response = await api.delete(`/protected/admin/groups/${encodeURIComponent(groupId)}`);
+    return response.data;
+};
+
+// Archiving: the owner (or an admin) archives; only admins restore or purge
+export const archiveAsset = async (id: string, reason: string) => {
+    const response = await api.delete(`/protected/assets/${id}`, { data: { reason } });
+    return response.data;
+};
+
+export const restoreAsset = async (id: string) => {
+    const response = await api.post(`/protected/admin/assets/${id}/restore`);
+    return response.data;
+};
+
+export const purgeAsset = async (id: string, reason: string) => {
+    const response = await api.delete(`/protected/admin/assets/${id}`, { data: { reason } });
+    return response.data;
+};
+
+// Token Ledger
+export const getTokenBalance = async (account?: string): Promise<TokenBalance> => {
+    const path = account ? `/protected/tokens/balance/${encodeURIComponent(account)}` : '/protected/tokens/balance';
+    const response = await api.get<TokenBalance>(path);
+    return response.data;
+};
+
+export const transferTokens = async (to: string, amount: number) => {
+    const response = await api.post('/protected/tokens/transfer', { to, amount });
+    return response.data;
+};
+
+export const getTokenMovements = async (account?: string): Promise<TokenMovement[]> => {
+    const params = account ? `?account=${encodeURIComponent(account)}` : '';
+    const response = await api.get<TokenMovement[]>(`/protected/tokens/movements${params}`);
+    return response.data || [];
+};
+
+export const mintTokens = async (account: string, amount: number) => {
+    const response = await api.post('/protected/admin/tokens/mint', { account, amount });
+    return response.data;
+};
+
+// Asset Type Registry
+export const getAssetTypes = async (): Promise<AssetTypeDef[]> => {
+    const response = await api.get<AssetTypeDef[]>('/asset-types');
+    return response.data;
+};
+
+export const setAssetType = async (name: string, description: string, attributes: AttributeDef[], requiredAttestations: string[] = []) => {
+    const response = await api.put(`/protected/admin/asset-types/${encodeURIComponent(name)}`, { description, attributes, required_attestations: requiredAttestations });
+    return response.data;
+};
+
+export const deleteAssetType = async (name: string) => {
+    const response = await api.delete(`/protected/admin/asset-types/${encodeURIComponent(name)}`);
+    return response.data;
+};
+
+// Auditor Attestations
+export const attestAsset = async (id: string, type: string, documentHash: string, validDays: number) => {
+    const response = await api.post<Attestation>(`/protected/assets/${id}/attestations`, { type, document_hash: documentHash, valid_days: validDays });
+    return response.data;
+};
+
+export const getAttestations = async (id: string, state: 'current' | 'expired' = 'current'): Promise<Attestation[]> => {
+    const response = await api.get<Attestation[]>(`/assets/${id}/attestations`, { params: { state } });
+    return response.data;
+};
+
+// Legal Freeze (admin)
+export const freezeAsset = async (id: string, caseRef: string, reason: string) => {
+    const response = await api.post(`/protected/admin/assets/${id}/freeze`, { case_ref: caseRef, reason });
+    return response.data;
+};
+
+export const unfreezeAsset = async (id: string, caseRef: string, reason: string) => {
+    const response = await api.post(`/protected/admin/assets/${id}/unfreeze`, { case_ref: caseRef, reason });
+    return response.data;
+};
+
+export const forceTransferAsset = async (id: string, newOwner: string, reason: string, documentHash: string) => {
+    const response = await api.post(`/protected/admin/assets/${id}/force-transfer`, { new_owner: newOwner, reason, document_hash: documentHash });
+    return response.data;
+};
+
+export const getFreezeHistory = async (id: string): Promise<FreezeRecord[]> => {
+    const response = await api.get<FreezeRecord[]>(`/protected/admin/assets/${id}/freezes`);
+    return response.data;
+};
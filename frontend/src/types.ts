@@ -7,6 +7,83 @@ export interface Asset {
     metadata_url: string;
     metadata_hash?: string;
     last_modified_by?: string;
+    totalUnits?: number;              // Fractional assets only
+    shares?: Record<string, number>;  // Holder -> units, fractional assets only
+    parentId?: string;                // Bundle parent, if attached
+    children?: string[];              // Attached assets that move with this one
+    viewers?: string[];
+    grants?: ViewerGrant[];           // Permission and expiry per viewer
+    custodian?: string;               // Borrower while on loan; owner is unchanged
+    custodySince?: number;            // Unix seconds
+    custodyUntil?: number;            // Loan due date, Unix seconds
+    archivedAt?: number;              // Set while status is "Archived" (soft-deleted)
+    archivedBy?: string;
+    archiveReason?: string;
+    attributes?: Record<string, AttributeValue>; // Custom attributes declared by the asset type
+    attestations?: Attestation[];
+    freeze?: FreezeRecord; // Active legal freeze; blocks every change and transfer
+    freezeHistory?: FreezeRecord[];
+}
+
+// One freeze or unfreeze of an asset under a legal case
+export interface FreezeRecord {
+    action: 'FREEZE' | 'UNFREEZE';
+    caseRef: string;
+    reason: string;
+    actor: string;
+    timestamp: number;
+    txId: string;
+}
+
+// An auditor's signed statement about an asset, backed by a document hash
+export interface Attestation {
+    id: string; // Transaction ID
+    type: string;
+    documentHash: string;
+    auditor: string;
+    auditorMsp: string;
+    certificateHash: string;
+    issuedAt: number;
+    validUntil: number;
+}
+
+export type AttributeValue = string | number | boolean;
+
+export type AttributeDataType = 'string' | 'number' | 'integer' | 'boolean' | 'date';
+
+export interface AttributeDef {
+    name: string;
+    dataType: AttributeDataType;
+    required: boolean;
+    description?: string;
+}
+
+// A registered asset type and the attributes its assets carry
+export interface AssetTypeDef {
+    name: string;
+    description?: string;
+    attributes: AttributeDef[];
+    requiredAttestations?: string[]; // Attestation types needed before a transfer executes
+    updatedAt: number;
+    updatedBy: string;
+}
+
+export type ViewerPermission = 'metadata' | 'full';
+
+// Admin-managed set of users; assets shared with "group:<id>" are visible to every member
+export interface ViewerGroup {
+    id: string;
+    members: string[];
+    updatedAt: number;
+    updatedBy: string;
+}
+
+export interface ViewerGrant {
+    viewer: string;
+    permission: ViewerPermission;
+    expiresAt?: number;               // Unix seconds; absent never expires
+    grantedBy: string;
+    grantedAt: number;
 }
 
 export interface User {
@@ -36,6 +113,20 @@ export interface DashboardStats {
     total_users: number;
     total_assets: number;
     pending_transfers: number;
+    force_transfers: number;
+    recent_force_transfers: ForceTransfer[];
+}
+
+// An admin's forced transfer, as recorded in the asset history
+export interface ForceTransfer {
+    tx_id: string;
+    asset_id: string;
+    from_owner: string;
+    to_owner: string;
+    actor_id: string;
+    reason: string;
+    document_hash: string; // SHA-256 of the authorising document
+    timestamp: string;
 }
 
 export interface UserStats extends User {
@@ -60,4 +151,89 @@ export interface PublicAsset {
     metadata_url: string;
     last_tx_id?: string;
     last_modified_by?: string;
+    total_units?: number;
+    shares?: Record<string, number>;
+    attributes?: Record<string, AttributeValue>;
+    frozen?: boolean;
+    frozen_case?: string;
+}
+
+// One holder's share of an asset (a whole asset is a single 100% holding)
+export interface Holding {
+    holder: string;
+    units: number;
+    totalUnits: number;
+    percent: number;
+}
+
+// A bundle: an asset and the assets attached to it, recursively
+export interface AssetTreeNode {
+    asset: Asset;
+    children: AssetTreeNode[];
+}
+
+export interface ImportRowResult {
+    row: number;
+    id: string;
+    status: 'created' | 'invalid' | 'skipped';
+    error?: string;
+}
+
+export interface ImportResult {
+    created: number;
+    rows: ImportRowResult[];
+    error?: string;
+}
+
+export interface TokenBalance {
+    account: string;
+    balance: number;
+    updatedAt: number;
+}
+
+// One entry of the token ledger (mint, user payment or settlement of a priced transfer)
+export interface TokenMovement {
+    tx_id: string;
+    kind: 'MINT' | 'TRANSFER' | 'SETTLEMENT';
+    from: string; // Empty for mints
+    to: string;
+    amount: number;
+    asset_id: string; // Settlements only
+    actor_id: string;
+    timestamp: string;
+}
+
+export interface StatusRule {
+    status: string;
+    transferable: boolean;
+    allowedNext: string[];
+    systemManaged: boolean;
+}
+
+export interface TransferPolicy {
+    scope: 'type' | 'asset' | '';
+    scopeId: string;
+    threshold: number;
+    approverRoles: string[];
+    approverUsers: string[];
+    updatedAt: number;
+    updatedBy: string;
+}
+
+// Sensitive asset fields kept in the private data collection (owner and granted viewers only)
+export interface AssetPrivateDetails {
+    assetId?: string;
+    purchasePrice?: number;
+    serialNumber?: string;
+    documents?: string[];
+    notes?: string;
+    updatedAt?: number;
+    updatedBy?: string;
+}
+
+// Cursor-paginated ledger list (follow bookmark while has_more)
+export interface Page<T> {
+    records: T[];
+    bookmark: string;
+    has_more: boolean;
 }
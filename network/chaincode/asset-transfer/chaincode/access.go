@@ -0,0 +1,248 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Viewer grants. Asset.Viewers stays the indexed list of viewer IDs; Asset.Grants records, per viewer,
+// what the grant allows and until when. Viewers without a grant record (granted before grants
+// existed, or seeded by InitLedger) keep permanent full access.
+//
+// A viewer ID is a user ID, "EVERYONE", "role:<Role>" (every user with that ledger role) or
+// "group:<name>" (every member of a ledger group, see groups.go). viewerPrincipals resolves a
+// user to all the viewer IDs that match them; the backend and Postgres resolve the same way.
+
+// Viewer permission levels
+const (
+	PermissionMetadata = "metadata" // The public asset record only
+	PermissionFull     = "full"     // The asset record and its private details
+)
+
+// publicViewer is the magic viewer ID that makes an asset visible to everybody
+const publicViewer = "EVERYONE"
+
+// Viewer ID prefixes granting access to a whole role or group
+const (
+	rolePrincipalPrefix  = "role:"
+	groupPrincipalPrefix = "group:"
+)
+
+// maxGrantSweep caps the asset IDs returned by GetAssetsWithExpiredGrants per call
+const maxGrantSweep = 50
+
+// ViewerGrant is the access one viewer has been given to an asset
+type ViewerGrant struct {
+	Viewer     string `json:"viewer"`
+	Permission string `json:"permission"`
+	ExpiresAt  int64  `json:"expiresAt,omitempty"` // Unix seconds; 0 never expires
+	GrantedBy  string `json:"grantedBy"`
+	GrantedAt  int64  `json:"grantedAt"`
+}
+
+// validatePermission fails unless the permission is one of the Permission* values
+func validatePermission(permission string) error {
+	if permission != PermissionMetadata && permission != PermissionFull {
+		return fmt.Errorf("invalid permission %q. Valid permissions: [%s %s]", permission, PermissionMetadata, PermissionFull)
+	}
+	return nil
+}
+
+// grantOf returns the grant record of a viewer, or nil when the viewer has none
+func grantOf(asset *Asset, viewerID string) *ViewerGrant {
+	for _, grant := range asset.Grants {
+		if grant.Viewer == viewerID {
+			return grant
+		}
+	}
+	return nil
+}
+
+// grantExpired reports whether a viewer's grant has run out at the given time
+func grantExpired(asset *Asset, viewerID string, now int64) bool {
+	grant := grantOf(asset, viewerID)
+	return grant != nil && grant.ExpiresAt > 0 && now >= grant.ExpiresAt
+}
+
+// hasViewerAccess reports whether one of the principals (see viewerPrincipals) is listed as a viewer
+// with an unexpired grant of at least the given permission. The EVERYONE grant never counts as full access.
+func hasViewerAccess(asset *Asset, principals []string, permission string, now int64) bool {
+	matches := map[string]bool{}
+	for _, principal := range principals {
+		matches[principal] = true
+	}
+	for _, viewer := range asset.Viewers {
+		if !matches[viewer] {
+			continue
+		}
+		if grantExpired(asset, viewer, now) {
+			continue
+		}
+		if permission == PermissionMetadata {
+			return true
+		}
+		if viewer == publicViewer {
+			continue
+		}
+		if grant := grantOf(asset, viewer); grant == nil || grant.Permission == PermissionFull {
+			return true
+		}
+	}
+	return false
+}
+
+// viewerPrincipals returns every viewer ID that grants access to the user: the user ID itself,
+// "role:<Role>" for their ledger role, "group:<name>" for each group they belong to, and EVERYONE.
+// Users without a ledger record (e.g. the System identity) only match their ID and EVERYONE.
+func (s *SmartContract) viewerPrincipals(ctx contractapi.TransactionContextInterface, userID string) ([]string, error) {
+	principals := []string{userID}
+	if user, err := s.ReadUser(ctx, userID); err == nil && user.Role != "" {
+		principals = append(principals, rolePrincipalPrefix+user.Role)
+	}
+	groups, err := groupsOf(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, groupID := range groups {
+		principals = append(principals, groupPrincipalPrefix+groupID)
+	}
+	return append(principals, publicViewer), nil
+}
+
+// validateViewer checks that a role or group viewer ID names an existing role or group
+func validateViewer(ctx contractapi.TransactionContextInterface, viewerID string) error {
+	if role := strings.TrimPrefix(viewerID, rolePrincipalPrefix); role != viewerID {
+		if role != RoleAdmin && role != RoleUser && role != RoleAuditor {
+			return fmt.Errorf("invalid role %q in viewer %s. Valid roles: [%s %s %s]", role, viewerID, RoleAdmin, RoleUser, RoleAuditor)
+		}
+	}
+	if groupID := strings.TrimPrefix(viewerID, groupPrincipalPrefix); groupID != viewerID {
+		group, err := readGroup(ctx, groupID)
+		if err != nil {
+			return err
+		}
+		if group == nil {
+			return fmt.Errorf("the group %s does not exist", groupID)
+		}
+	}
+	return nil
+}
+
+// GetViewerPrincipals returns the viewer IDs that grant access to a user (see viewerPrincipals).
+// The backend uses it to filter asset lists exactly like the chaincode does.
+func (s *SmartContract) GetViewerPrincipals(ctx contractapi.TransactionContextInterface, userID string) ([]string, error) {
+	return s.viewerPrincipals(ctx, userID)
+}
+
+// removeViewer drops a viewer and its grant record from the asset
+func removeViewer(asset *Asset, viewerID string) {
+	viewers := []string{}
+	for _, v := range asset.Viewers {
+		if v != viewerID {
+			viewers = append(viewers, v)
+		}
+	}
+	asset.Viewers = viewers
+
+	var grants []*ViewerGrant
+	for _, grant := range asset.Grants {
+		if grant.Viewer != viewerID {
+			grants = append(grants, grant)
+		}
+	}
+	asset.Grants = grants
+}
+
+// expiredViewers returns the viewers of the asset whose grants have run out
+func expiredViewers(asset *Asset, now int64) []string {
+	expired := []string{}
+	for _, viewer := range asset.Viewers {
+		if grantExpired(asset, viewer, now) {
+			expired = append(expired, viewer)
+		}
+	}
+	return expired
+}
+
+// GetAssetsWithExpiredGrants returns up to limit IDs of assets holding expired viewer grants.
+// The grant sweeper evaluates it, then submits RevokeExpiredGrants for each asset.
+func (s *SmartContract) GetAssetsWithExpiredGrants(ctx contractapi.TransactionContextInterface, limit int) ([]string, error) {
+	if limit <= 0 || limit > maxGrantSweep {
+		limit = maxGrantSweep
+	}
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetObjectType, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assets: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	assetIDs := []string{}
+	for resultsIterator.HasNext() && len(assetIDs) < limit {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var asset Asset
+		if err := json.Unmarshal(queryResponse.Value, &asset); err != nil {
+			continue
+		}
+		// Frozen assets keep their grants (expired ones grant nothing) until the freeze is lifted
+		if !isFrozen(&asset) && len(expiredViewers(&asset, timestamp.Seconds)) > 0 {
+			assetIDs = append(assetIDs, asset.ID)
+		}
+	}
+	return assetIDs, nil
+}
+
+// RevokeExpiredGrants removes every expired viewer grant of an asset and returns how many were removed.
+// It is called by the grant sweeper (System identity) and emits AccessRevoked like RevokeAccess.
+// Unlike RevokeAccess it also runs while a transfer is pending: an expired grant must not outlive its window.
+func (s *SmartContract) RevokeExpiredGrants(ctx contractapi.TransactionContextInterface, assetID string) (int, error) {
+	if err := requireRole(ctx, RoleSystem, RoleAdmin); err != nil {
+		return 0, err
+	}
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return 0, err
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return 0, err
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return 0, err
+	}
+	expired := expiredViewers(asset, timestamp.Seconds)
+	if len(expired) == 0 {
+		return 0, nil
+	}
+	for _, viewer := range expired {
+		removeViewer(asset, viewer)
+	}
+	asset.UpdatedAt = timestamp.Seconds
+	asset.LastModifiedBy = callerID
+	asset.Sequence = asset.Sequence + 1
+
+	assetJSON, err := putAsset(ctx, asset)
+	if err != nil {
+		return 0, err
+	}
+	if err := ctx.GetStub().SetEvent("AccessRevoked", assetJSON); err != nil {
+		return 0, err
+	}
+	return len(expired), nil
+}
@@ -0,0 +1,178 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Archiving. Assets are no longer deleted outright: ArchiveAsset moves an asset into the
+// system-managed Archived status, recording who archived it, when and why, and freezes it.
+// Only admins can bring an archived asset back (RestoreAsset) or remove it from the world
+// state for good (PurgeAsset). The ledger history of a purged asset stays on the chain.
+
+// AssetPurgedEvent is the payload of AssetPurged; it carries the last state of the asset
+type AssetPurgedEvent struct {
+	Asset  *Asset `json:"asset"`
+	Reason string `json:"reason"`
+	Actor  string `json:"actor"`
+}
+
+// isArchived reports whether the asset has been archived
+func isArchived(asset *Asset) bool {
+	return asset.Status == AssetStatusArchived
+}
+
+// requireNotArchived fails if the asset is archived
+func requireNotArchived(asset *Asset) error {
+	if isArchived(asset) {
+		return fmt.Errorf("asset %s was archived by %s (%s); an admin must restore it first", asset.ID, asset.ArchivedBy, asset.ArchiveReason)
+	}
+	return nil
+}
+
+// ArchiveAsset soft-deletes an asset. The owner or an admin may archive it, with a reason.
+// Archived assets keep their viewers and private details but cannot be changed, shared or transferred.
+func (s *SmartContract) ArchiveAsset(ctx contractapi.TransactionContextInterface, id string, reason string) error {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("a reason is required to archive an asset")
+	}
+
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+	if asset.Owner != callerID {
+		if err := requireAdmin(ctx); err != nil {
+			return fmt.Errorf("only the owner or an admin can archive an asset. Owner: %s, Caller: %s", asset.Owner, callerID)
+		}
+	} else if err := requireSoleHolder(asset, callerID); err != nil {
+		return err
+	}
+	if err := requireNotArchived(asset); err != nil {
+		return err
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return err
+	}
+	if err := requireNoTransferLock(asset); err != nil {
+		return err
+	}
+	if err := requireNoBundle(asset); err != nil {
+		return err
+	}
+	if err := requireNotOnLoan(asset); err != nil {
+		return err
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+
+	asset.StatusBeforeArchive = asset.Status
+	asset.Status = AssetStatusArchived
+	asset.ArchivedAt = timestamp.Seconds
+	asset.ArchivedBy = callerID
+	asset.ArchiveReason = reason
+	return s.putArchiveChange(ctx, "AssetArchived", asset, callerID, timestamp.Seconds)
+}
+
+// RestoreAsset brings an archived asset back with the status it had before it was archived (admin only)
+func (s *SmartContract) RestoreAsset(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	adminID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !isArchived(asset) {
+		return fmt.Errorf("asset %s is not archived", id)
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return err
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+
+	asset.Status = asset.StatusBeforeArchive
+	if asset.Status == "" {
+		asset.Status = AssetStatusOwned
+	}
+	asset.StatusBeforeArchive = ""
+	asset.ArchivedAt = 0
+	asset.ArchivedBy = ""
+	asset.ArchiveReason = ""
+	return s.putArchiveChange(ctx, "AssetRestored", asset, adminID, timestamp.Seconds)
+}
+
+// PurgeAsset removes an archived asset and its private details from the world state (admin only).
+// The asset must be archived first, so a purge is always a deliberate second step.
+func (s *SmartContract) PurgeAsset(ctx contractapi.TransactionContextInterface, id string, reason string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	adminID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("a reason is required to purge an asset")
+	}
+
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !isArchived(asset) {
+		return fmt.Errorf("asset %s must be archived before it can be purged", id)
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return err
+	}
+
+	key, err := assetKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return fmt.Errorf("failed to purge asset %s: %v", id, err)
+	}
+	if err := deleteAssetPrivateDetails(ctx, id); err != nil {
+		return err
+	}
+
+	eventJSON, err := json.Marshal(AssetPurgedEvent{Asset: asset, Reason: reason, Actor: adminID})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent("AssetPurged", eventJSON)
+}
+
+// putArchiveChange stores an archived or restored asset and emits the event with the asset as payload
+func (s *SmartContract) putArchiveChange(ctx contractapi.TransactionContextInterface, eventName string, asset *Asset, actorID string, now int64) error {
+	asset.UpdatedAt = now
+	asset.LastModifiedBy = actorID
+	asset.Sequence = asset.Sequence + 1
+
+	assetJSON, err := putAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent(eventName, assetJSON)
+}
@@ -0,0 +1,292 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Asset type registry. Admins register asset types, each declaring the custom attributes its
+// assets carry: a name, a data type and whether it is required. CreateAsset, UpdateAsset and
+// CreateAssetsBatch check Asset.Attributes against the definition of the asset's type.
+// Types that are not registered stay free-form but cannot carry attributes. A type can also
+// list the auditor attestations its assets need before they can be transferred (see attestations.go).
+
+// Attribute data types
+const (
+	AttributeString  = "string"
+	AttributeNumber  = "number"
+	AttributeInteger = "integer"
+	AttributeBoolean = "boolean"
+	AttributeDate    = "date" // "YYYY-MM-DD"
+)
+
+// attributeDateLayout is the layout of date attributes
+const attributeDateLayout = "2006-01-02"
+
+// AttributeDef declares one custom attribute of an asset type
+type AttributeDef struct {
+	Name        string `json:"name"`
+	DataType    string `json:"dataType"` // One of the Attribute* values
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+// AssetTypeDef is a registered asset type and its attributes
+type AssetTypeDef struct {
+	DocType              string          `json:"docType"` // "asset_type"
+	Name                 string          `json:"name"`
+	Description          string          `json:"description,omitempty"`
+	Attributes           []*AttributeDef `json:"attributes"`
+	RequiredAttestations []string        `json:"requiredAttestations,omitempty"` // Attestation types that must be valid for a transfer to execute
+	UpdatedAt            int64           `json:"updatedAt"`
+	UpdatedBy            string          `json:"updatedBy"`
+	Sequence             uint64          `json:"sequence"`
+}
+
+// validateAttributeDefs checks attribute names are present and unique and data types are known
+func validateAttributeDefs(defs []*AttributeDef) error {
+	seen := map[string]bool{}
+	for _, def := range defs {
+		if def == nil || def.Name == "" {
+			return fmt.Errorf("every attribute needs a name")
+		}
+		if seen[def.Name] {
+			return fmt.Errorf("duplicate attribute %s", def.Name)
+		}
+		seen[def.Name] = true
+		switch def.DataType {
+		case AttributeString, AttributeNumber, AttributeInteger, AttributeBoolean, AttributeDate:
+		default:
+			return fmt.Errorf("invalid data type %q for attribute %s. Valid types: [%s %s %s %s %s]",
+				def.DataType, def.Name, AttributeString, AttributeNumber, AttributeInteger, AttributeBoolean, AttributeDate)
+		}
+	}
+	return nil
+}
+
+// checkAttributeValue fails unless the JSON-decoded value matches the attribute's data type
+func checkAttributeValue(def *AttributeDef, value interface{}) error {
+	ok := false
+	switch def.DataType {
+	case AttributeString:
+		_, ok = value.(string)
+	case AttributeNumber:
+		_, ok = value.(float64)
+	case AttributeInteger:
+		n, isNumber := value.(float64)
+		ok = isNumber && n == math.Trunc(n)
+	case AttributeBoolean:
+		_, ok = value.(bool)
+	case AttributeDate:
+		if s, isString := value.(string); isString {
+			_, err := time.Parse(attributeDateLayout, s)
+			ok = err == nil
+		}
+	}
+	if !ok {
+		return fmt.Errorf("attribute %s must be a %s, got %v", def.Name, def.DataType, value)
+	}
+	return nil
+}
+
+// readAssetType returns the registered type, or nil when it is not registered
+func readAssetType(ctx contractapi.TransactionContextInterface, name string) (*AssetTypeDef, error) {
+	key, err := assetTypeKey(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	typeJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if typeJSON == nil {
+		return nil, nil
+	}
+
+	var def AssetTypeDef
+	if err := json.Unmarshal(typeJSON, &def); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// parseAttributes decodes an attributes JSON object; an empty string means no attributes
+func parseAttributes(attributesJSON string) (map[string]interface{}, error) {
+	if attributesJSON == "" {
+		return nil, nil
+	}
+	var attributes map[string]interface{}
+	if err := json.Unmarshal([]byte(attributesJSON), &attributes); err != nil {
+		return nil, fmt.Errorf("invalid attributes JSON, expected an object: %v", err)
+	}
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+	return attributes, nil
+}
+
+// validateAttributes checks asset attributes against the definition of the asset type:
+// every required attribute present, no undeclared attribute, and every value of the declared type
+func validateAttributes(ctx contractapi.TransactionContextInterface, assetType string, attributes map[string]interface{}) error {
+	def, err := readAssetType(ctx, assetType)
+	if err != nil {
+		return err
+	}
+	if def == nil {
+		if len(attributes) > 0 {
+			return fmt.Errorf("asset type %s is not registered and cannot carry attributes", assetType)
+		}
+		return nil
+	}
+
+	declared := map[string]*AttributeDef{}
+	for _, attr := range def.Attributes {
+		declared[attr.Name] = attr
+		if _, ok := attributes[attr.Name]; attr.Required && !ok {
+			return fmt.Errorf("attribute %s is required for asset type %s", attr.Name, assetType)
+		}
+	}
+
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Report the same error on every peer
+	for _, name := range names {
+		attr, ok := declared[name]
+		if !ok {
+			return fmt.Errorf("attribute %s is not declared by asset type %s", name, assetType)
+		}
+		if err := checkAttributeValue(attr, attributes[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetAssetType registers an asset type or replaces its definition (admin only).
+// definitionJSON is {"description": "...", "attributes": [{"name", "dataType", "required", "description"}],
+// "requiredAttestations": ["inspection", ...]}.
+// Existing assets are not re-validated; they are checked against the new definition on their next update.
+func (s *SmartContract) SetAssetType(ctx contractapi.TransactionContextInterface, name string, definitionJSON string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	adminID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("an asset type name is required")
+	}
+
+	var definition struct {
+		Description          string          `json:"description"`
+		Attributes           []*AttributeDef `json:"attributes"`
+		RequiredAttestations []string        `json:"requiredAttestations"`
+	}
+	if err := json.Unmarshal([]byte(definitionJSON), &definition); err != nil {
+		return fmt.Errorf("invalid asset type JSON: %v", err)
+	}
+	if err := validateAttributeDefs(definition.Attributes); err != nil {
+		return err
+	}
+	for _, attestationType := range definition.RequiredAttestations {
+		if strings.TrimSpace(attestationType) == "" {
+			return fmt.Errorf("required attestation types cannot be empty")
+		}
+	}
+
+	def, err := readAssetType(ctx, name)
+	if err != nil {
+		return err
+	}
+	if def == nil {
+		def = &AssetTypeDef{DocType: "asset_type", Name: name}
+	}
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+
+	def.Description = definition.Description
+	def.Attributes = definition.Attributes
+	if def.Attributes == nil {
+		def.Attributes = []*AttributeDef{}
+	}
+	def.RequiredAttestations = definition.RequiredAttestations
+	def.UpdatedAt = timestamp.Seconds
+	def.UpdatedBy = adminID
+	def.Sequence = def.Sequence + 1
+
+	defJSON, err := putAssetType(ctx, def)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent("AssetTypeSet", defJSON)
+}
+
+// DeleteAssetType unregisters an asset type (admin only). Assets of that type keep their
+// attributes but can no longer carry any once they are updated.
+func (s *SmartContract) DeleteAssetType(ctx contractapi.TransactionContextInterface, name string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if _, err := activeCallerID(ctx); err != nil {
+		return err
+	}
+	if _, err := s.GetAssetType(ctx, name); err != nil {
+		return err
+	}
+
+	key, err := assetTypeKey(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return fmt.Errorf("failed to delete asset type %s: %v", name, err)
+	}
+	return ctx.GetStub().SetEvent("AssetTypeDeleted", []byte(name))
+}
+
+// GetAssetType returns the definition of a registered asset type
+func (s *SmartContract) GetAssetType(ctx contractapi.TransactionContextInterface, name string) (*AssetTypeDef, error) {
+	def, err := readAssetType(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if def == nil {
+		return nil, fmt.Errorf("the asset type %s is not registered", name)
+	}
+	return def, nil
+}
+
+// GetAllAssetTypes returns every registered asset type
+func (s *SmartContract) GetAllAssetTypes(ctx contractapi.TransactionContextInterface) ([]*AssetTypeDef, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetTypeObjectType, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get asset types: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	defs := []*AssetTypeDef{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var def AssetTypeDef
+		if err := json.Unmarshal(queryResponse.Value, &def); err != nil {
+			return nil, err
+		}
+		defs = append(defs, &def)
+	}
+	return defs, nil
+}
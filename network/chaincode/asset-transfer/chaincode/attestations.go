@@ -0,0 +1,175 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Auditor attestations. Auditors attest to an asset (an inspection, a valuation, a compliance
+// check) by recording the hash of the supporting document and how long the attestation holds.
+// Each record names the auditor, their MSP and certificate and the transaction that wrote it,
+// so it can be traced back to the auditor's signature in the block. An asset type can list
+// attestation types (AssetTypeDef.RequiredAttestations) that must be valid for its assets to
+// change hands.
+
+// documentHashPattern matches a hex-encoded SHA-256 digest
+var documentHashPattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// Attestation is one auditor's signed statement about an asset
+type Attestation struct {
+	ID              string `json:"id"` // Transaction ID of the attestation
+	Type            string `json:"type"`
+	DocumentHash    string `json:"documentHash"` // SHA-256 of the supporting document
+	Auditor         string `json:"auditor"`
+	AuditorMSP      string `json:"auditorMsp"`
+	CertificateHash string `json:"certificateHash"` // SHA-256 of the auditor's signing certificate
+	IssuedAt        int64  `json:"issuedAt"`
+	ValidUntil      int64  `json:"validUntil"`
+}
+
+// AttestationEvent is the payload of AssetAttested
+type AttestationEvent struct {
+	Asset       *Asset       `json:"asset"`
+	Attestation *Attestation `json:"attestation"`
+}
+
+// attestationValid reports whether an attestation still holds at now
+func attestationValid(attestation *Attestation, now int64) bool {
+	return now <= attestation.ValidUntil
+}
+
+// hasValidAttestation reports whether the asset holds a valid attestation of the given type
+func hasValidAttestation(asset *Asset, attestationType string, now int64) bool {
+	for _, attestation := range asset.Attestations {
+		if attestation.Type == attestationType && attestationValid(attestation, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// requireAttestations fails unless the asset holds a valid attestation of every type its asset type requires
+func requireAttestations(ctx contractapi.TransactionContextInterface, asset *Asset, now int64) error {
+	def, err := readAssetType(ctx, asset.Type)
+	if err != nil {
+		return err
+	}
+	if def == nil {
+		return nil
+	}
+	for _, required := range def.RequiredAttestations {
+		if !hasValidAttestation(asset, required, now) {
+			return fmt.Errorf("asset %s cannot be transferred: asset type %s requires a valid %s attestation", asset.ID, asset.Type, required)
+		}
+	}
+	return nil
+}
+
+// AttestAsset records an auditor's attestation on an asset (auditors only).
+// documentHash is the SHA-256 of the supporting document; validUntil is in Unix seconds.
+func (s *SmartContract) AttestAsset(ctx contractapi.TransactionContextInterface, assetID string, attestationType string, documentHash string, validUntil int64) (*Attestation, error) {
+	if err := requireRole(ctx, RoleAuditor); err != nil {
+		return nil, err
+	}
+	auditorID, err := activeCallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(attestationType) == "" {
+		return nil, fmt.Errorf("an attestation type is required")
+	}
+	if !documentHashPattern.MatchString(documentHash) {
+		return nil, fmt.Errorf("documentHash must be a hex-encoded SHA-256 digest")
+	}
+
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireNotArchived(asset); err != nil {
+		return nil, err
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	now := timestamp.Seconds
+	if validUntil <= now {
+		return nil, fmt.Errorf("validUntil must be in the future, got %d", validUntil)
+	}
+
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+	cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil || cert == nil {
+		return nil, fmt.Errorf("failed to read client certificate: %v", err)
+	}
+	certHash := sha256.Sum256(cert.Raw)
+
+	attestation := &Attestation{
+		ID:              ctx.GetStub().GetTxID(),
+		Type:            attestationType,
+		DocumentHash:    strings.ToLower(documentHash),
+		Auditor:         auditorID,
+		AuditorMSP:      mspID,
+		CertificateHash: hex.EncodeToString(certHash[:]),
+		IssuedAt:        now,
+		ValidUntil:      validUntil,
+	}
+	asset.Attestations = append(asset.Attestations, attestation)
+	asset.UpdatedAt = now
+	asset.LastModifiedBy = auditorID
+	asset.Sequence = asset.Sequence + 1
+
+	if _, err := putAsset(ctx, asset); err != nil {
+		return nil, err
+	}
+
+	eventJSON, err := json.Marshal(AttestationEvent{Asset: asset, Attestation: attestation})
+	if err != nil {
+		return nil, err
+	}
+	return attestation, ctx.GetStub().SetEvent("AssetAttested", eventJSON)
+}
+
+// GetCurrentAttestations returns the attestations on an asset that are still valid
+func (s *SmartContract) GetCurrentAttestations(ctx contractapi.TransactionContextInterface, assetID string) ([]*Attestation, error) {
+	return s.filterAttestations(ctx, assetID, true)
+}
+
+// GetExpiredAttestations returns the attestations on an asset that have lapsed
+func (s *SmartContract) GetExpiredAttestations(ctx contractapi.TransactionContextInterface, assetID string) ([]*Attestation, error) {
+	return s.filterAttestations(ctx, assetID, false)
+}
+
+// filterAttestations returns the valid (or lapsed) attestations on an asset as of the transaction time
+func (s *SmartContract) filterAttestations(ctx contractapi.TransactionContextInterface, assetID string, valid bool) ([]*Attestation, error) {
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+
+	attestations := []*Attestation{}
+	for _, attestation := range asset.Attestations {
+		if attestationValid(attestation, timestamp.Seconds) == valid {
+			attestations = append(attestations, attestation)
+		}
+	}
+	return attestations, nil
+}
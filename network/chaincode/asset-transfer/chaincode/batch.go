@@ -0,0 +1,171 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Batch creation. Every entry is validated before anything is written, and any error
+// rolls back the whole transaction, so a batch commits all-or-nothing in a single block.
+
+// maxAssetBatch caps the number of assets per CreateAssetsBatch transaction,
+// keeping the write set and the event payload small enough to endorse reliably
+const maxAssetBatch = 100
+
+// AssetBatchEntry is one asset of a CreateAssetsBatch request
+type AssetBatchEntry struct {
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Type         string                 `json:"type"`
+	Owner        string                 `json:"owner"` // Optional; defaults to the caller and must match the certificate
+	Status       string                 `json:"status"`
+	MetadataURL  string                 `json:"metadata_url"`
+	MetadataHash string                 `json:"metadata_hash"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"` // Checked against the asset type like CreateAsset
+}
+
+// AssetBatchResult is the validation outcome of one entry; Error is empty when the entry is valid
+type AssetBatchResult struct {
+	Index int    `json:"index"`
+	ID    string `json:"id"`
+	Error string `json:"error,omitempty"`
+}
+
+// AssetsCreatedEvent is the payload of AssetsCreated. Fabric delivers only one event per
+// transaction, so a single event carries every asset created by the batch.
+type AssetsCreatedEvent struct {
+	Assets []*Asset `json:"assets"`
+}
+
+// parseAssetBatch decodes and size-checks a CreateAssetsBatch request
+func parseAssetBatch(assetsJSON string) ([]AssetBatchEntry, error) {
+	var entries []AssetBatchEntry
+	if err := json.Unmarshal([]byte(assetsJSON), &entries); err != nil {
+		return nil, fmt.Errorf("invalid assets JSON: %v", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("the batch contains no assets")
+	}
+	if len(entries) > maxAssetBatch {
+		return nil, fmt.Errorf("the batch contains %d assets (max %d)", len(entries), maxAssetBatch)
+	}
+	return entries, nil
+}
+
+// validateAssetBatch checks every entry against the rules of CreateAsset and against the rest of the batch.
+// It returns one result per entry and whether all of them are valid.
+func (s *SmartContract) validateAssetBatch(ctx contractapi.TransactionContextInterface, entries []AssetBatchEntry, callerID string) ([]*AssetBatchResult, bool, error) {
+	results := []*AssetBatchResult{}
+	seen := map[string]int{}
+	valid := true
+	for i, entry := range entries {
+		result := &AssetBatchResult{Index: i, ID: entry.ID}
+		results = append(results, result)
+
+		if entry.ID == "" {
+			result.Error = "asset ID is required"
+		} else if first, ok := seen[entry.ID]; ok {
+			result.Error = fmt.Sprintf("duplicate asset ID %s (also at index %d)", entry.ID, first)
+		} else if entry.Owner != "" && entry.Owner != callerID {
+			result.Error = fmt.Sprintf("identity mismatch: supplied %s but certificate belongs to %s", entry.Owner, callerID)
+		} else if err := validateAssetStatus(entry.Status); err != nil {
+			result.Error = err.Error()
+		} else if err := validateAttributes(ctx, entry.Type, entry.Attributes); err != nil {
+			result.Error = err.Error()
+		} else {
+			exists, err := s.AssetExists(ctx, entry.ID)
+			if err != nil {
+				return nil, false, err
+			}
+			if exists {
+				result.Error = fmt.Sprintf("the asset %s already exists", entry.ID)
+			}
+		}
+
+		if entry.ID != "" {
+			if _, ok := seen[entry.ID]; !ok {
+				seen[entry.ID] = i
+			}
+		}
+		if result.Error != "" {
+			valid = false
+		}
+	}
+	return results, valid, nil
+}
+
+// ValidateAssetsBatch reports, entry by entry, whether CreateAssetsBatch would accept the batch.
+// It writes nothing and is meant to be evaluated before submitting.
+func (s *SmartContract) ValidateAssetsBatch(ctx contractapi.TransactionContextInterface, assetsJSON string) ([]*AssetBatchResult, error) {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseAssetBatch(assetsJSON)
+	if err != nil {
+		return nil, err
+	}
+	results, _, err := s.validateAssetBatch(ctx, entries, callerID)
+	return results, err
+}
+
+// CreateAssetsBatch creates up to maxAssetBatch assets owned by the caller in one transaction.
+// assetsJSON is an array of AssetBatchEntry. If any entry is invalid nothing is written.
+func (s *SmartContract) CreateAssetsBatch(ctx contractapi.TransactionContextInterface, assetsJSON string) error {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := parseAssetBatch(assetsJSON)
+	if err != nil {
+		return err
+	}
+
+	results, valid, err := s.validateAssetBatch(ctx, entries, callerID)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		for _, result := range results {
+			if result.Error != "" {
+				return fmt.Errorf("batch rejected, entry %d (%s): %s", result.Index, result.ID, result.Error)
+			}
+		}
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+
+	event := AssetsCreatedEvent{Assets: []*Asset{}}
+	for _, entry := range entries {
+		asset := &Asset{
+			DocType:        "asset",
+			ID:             entry.ID,
+			Name:           entry.Name,
+			Type:           entry.Type,
+			Owner:          callerID,
+			Status:         entry.Status,
+			MetadataURL:    entry.MetadataURL,
+			MetadataHash:   entry.MetadataHash,
+			Attributes:     entry.Attributes,
+			Viewers:        []string{}, // Default: Private to Owner
+			UpdatedAt:      timestamp.Seconds,
+			LastModifiedBy: callerID,
+			Sequence:       1,
+		}
+		if _, err := putAsset(ctx, asset); err != nil {
+			return err
+		}
+		event.Assets = append(event.Assets, asset)
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent("AssetsCreated", eventJSON)
+}
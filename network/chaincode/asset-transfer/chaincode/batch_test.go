@@ -0,0 +1,58 @@
+package chaincode
+
+import "testing"
+
+func TestCreateAssetsBatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		batch   string
+		wantErr bool
+	}{
+		{"owned by the caller", `[{"id":"a1","name":"Laptop","type":"Electronics","status":"Available"},{"id":"a2","name":"Phone","type":"Electronics","owner":"alice","status":"Owned"}]`, false},
+		{"owner is not the caller", `[{"id":"a1","name":"Laptop","status":"Available"},{"id":"a2","name":"Phone","owner":"bob","status":"Available"}]`, true},
+		{"duplicate ID", `[{"id":"a1","name":"Laptop","status":"Available"},{"id":"a1","name":"Phone","status":"Available"}]`, true},
+		{"invalid status", `[{"id":"a1","name":"Laptop","status":"Available"},{"id":"a2","name":"Phone","status":"Pre-order"}]`, true},
+		{"existing asset", `[{"id":"a1","name":"Laptop","status":"Available"},{"id":"existing","name":"Phone","status":"Available"}]`, true},
+		{"empty", `[]`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := newTestLedger(t)
+			ledger.seedUsers(map[string]string{"alice": RoleUser})
+			ledger.seedAsset(&Asset{ID: "existing", Owner: "carol", Status: AssetStatusOwned})
+			contract := &SmartContract{}
+
+			err := contract.CreateAssetsBatch(ledger.as("alice", RoleUser), tt.batch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateAssetsBatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// All-or-nothing: a rejected batch leaves even its valid entries unwritten
+			exists, err := contract.AssetExists(ledger.as("alice", RoleUser), "a1")
+			if err != nil {
+				t.Fatalf("AssetExists: %v", err)
+			}
+			if exists == tt.wantErr {
+				t.Fatalf("asset a1 exists = %v after a batch with wantErr %v", exists, tt.wantErr)
+			}
+			if !tt.wantErr {
+				if got := ledger.asset("a2"); got.Owner != "alice" {
+					t.Fatalf("asset a2 owner = %q, want alice", got.Owner)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateAssetsBatchRefusesLockedCaller(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.seedUsers(map[string]string{"alice": RoleUser})
+	contract := &SmartContract{}
+
+	if err := contract.SetUserStatus(ledger.as("admin", RoleAdmin), "alice", UserStatusLocked); err != nil {
+		t.Fatalf("SetUserStatus: %v", err)
+	}
+	if err := contract.CreateAssetsBatch(ledger.as("alice", RoleUser), `[{"id":"a1","name":"Laptop","status":"Available"}]`); err == nil {
+		t.Fatal("a locked caller created assets")
+	}
+}
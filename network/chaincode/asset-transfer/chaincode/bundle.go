@@ -0,0 +1,282 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Composite assets. A child is attached to exactly one parent and from then on moves with it:
+// transferring the root of a bundle through the multi-sig flow carries every descendant in the
+// same transaction. Attached children cannot be transferred, fractionalized or deleted on their own.
+
+// maxBundleDepth is the maximum number of levels below the root of a bundle
+// (e.g. vehicle -> trailer -> toolbox -> tool)
+const maxBundleDepth = 3
+
+// BundleEvent is the payload of AssetAttached and AssetDetached: both sides of the link after the change
+type BundleEvent struct {
+	Parent *Asset `json:"parent"`
+	Child  *Asset `json:"child"`
+}
+
+// AssetTreeNode is one asset of a bundle with its attached children
+type AssetTreeNode struct {
+	Asset    *Asset           `json:"asset"`
+	Children []*AssetTreeNode `json:"children"`
+}
+
+// requireNotAttached fails when the asset is attached to a parent and therefore only moves with it
+func requireNotAttached(asset *Asset) error {
+	if asset.ParentID != "" {
+		return fmt.Errorf("asset %s is attached to %s; detach it or transfer the parent", asset.ID, asset.ParentID)
+	}
+	return nil
+}
+
+// requireNoBundle fails when the asset is part of a bundle, as parent or child
+func requireNoBundle(asset *Asset) error {
+	if err := requireNotAttached(asset); err != nil {
+		return err
+	}
+	if len(asset.Children) > 0 {
+		return fmt.Errorf("asset %s has attached assets %v; detach them first", asset.ID, asset.Children)
+	}
+	return nil
+}
+
+// checkBundleLink fails if attaching the child to the parent would close a cycle (the child already
+// sits above the parent) or nest the bundle deeper than maxBundleDepth. ancestors are the IDs above
+// the parent and childHeight is the height of the child's own subtree.
+func checkBundleLink(parentID string, childID string, ancestors []string, childHeight int) error {
+	for _, ancestorID := range ancestors {
+		if ancestorID == childID {
+			return fmt.Errorf("attaching %s to %s would create a cycle", childID, parentID)
+		}
+	}
+	if depth := len(ancestors) + 1 + childHeight; depth > maxBundleDepth {
+		return fmt.Errorf("attaching %s to %s would nest the bundle %d levels deep (max %d)", childID, parentID, depth, maxBundleDepth)
+	}
+	return nil
+}
+
+// ancestorsOf returns the IDs above the asset, nearest first
+func (s *SmartContract) ancestorsOf(ctx contractapi.TransactionContextInterface, asset *Asset) ([]string, error) {
+	ancestors := []string{}
+	for current := asset; current.ParentID != ""; {
+		// A corrupted link must not loop forever
+		if len(ancestors) > maxBundleDepth {
+			return nil, fmt.Errorf("bundle above asset %s is deeper than %d levels", asset.ID, maxBundleDepth)
+		}
+		ancestors = append(ancestors, current.ParentID)
+		parent, err := s.ReadAsset(ctx, current.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		current = parent
+	}
+	return ancestors, nil
+}
+
+// descendantsOf returns every asset attached below the given one, breadth first, with the height of its subtree
+func (s *SmartContract) descendantsOf(ctx contractapi.TransactionContextInterface, asset *Asset) ([]*Asset, int, error) {
+	descendants := []*Asset{}
+	level := []*Asset{asset}
+	height := 0
+	for len(level) > 0 {
+		next := []*Asset{}
+		for _, node := range level {
+			for _, childID := range node.Children {
+				child, err := s.ReadAsset(ctx, childID)
+				if err != nil {
+					return nil, 0, err
+				}
+				next = append(next, child)
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		height++
+		if height > maxBundleDepth {
+			return nil, 0, fmt.Errorf("bundle below asset %s is deeper than %d levels", asset.ID, maxBundleDepth)
+		}
+		descendants = append(descendants, next...)
+		level = next
+	}
+	return descendants, height, nil
+}
+
+// AttachAsset attaches a child asset to a parent. The caller must own both outright,
+// the child must not already be attached, and the link may neither close a cycle nor
+// make the bundle deeper than maxBundleDepth.
+func (s *SmartContract) AttachAsset(ctx contractapi.TransactionContextInterface, parentID string, childID string) error {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	if parentID == childID {
+		return fmt.Errorf("an asset cannot be attached to itself")
+	}
+
+	parent, err := s.ReadAsset(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	child, err := s.ReadAsset(ctx, childID)
+	if err != nil {
+		return err
+	}
+	for _, asset := range []*Asset{parent, child} {
+		if asset.Owner != callerID {
+			return fmt.Errorf("only the owner can bundle asset %s. Owner: %s, Caller: %s", asset.ID, asset.Owner, callerID)
+		}
+		if isFractional(asset) {
+			return fmt.Errorf("asset %s is held in shares and cannot be bundled", asset.ID)
+		}
+		if err := requireNoTransferLock(asset); err != nil {
+			return err
+		}
+		if err := requireNotArchived(asset); err != nil {
+			return err
+		}
+		if err := requireNotFrozen(asset); err != nil {
+			return err
+		}
+		if err := requireNotOnLoan(asset); err != nil {
+			return err
+		}
+	}
+	if err := requireNotAttached(child); err != nil {
+		return err
+	}
+
+	ancestors, err := s.ancestorsOf(ctx, parent)
+	if err != nil {
+		return err
+	}
+	_, childHeight, err := s.descendantsOf(ctx, child)
+	if err != nil {
+		return err
+	}
+	if err := checkBundleLink(parentID, childID, ancestors, childHeight); err != nil {
+		return err
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+
+	parent.Children = append(parent.Children, childID)
+	child.ParentID = parentID
+	return s.putBundleLink(ctx, "AssetAttached", parent, child, callerID, timestamp.Seconds)
+}
+
+// DetachAsset removes a child from its parent; the child stays with the parent's owner as a standalone asset
+func (s *SmartContract) DetachAsset(ctx contractapi.TransactionContextInterface, parentID string, childID string) error {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	parent, err := s.ReadAsset(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	if parent.Owner != callerID {
+		return fmt.Errorf("only the owner can detach assets from %s. Owner: %s, Caller: %s", parentID, parent.Owner, callerID)
+	}
+	child, err := s.ReadAsset(ctx, childID)
+	if err != nil {
+		return err
+	}
+	if child.ParentID != parentID {
+		return fmt.Errorf("asset %s is not attached to %s", childID, parentID)
+	}
+	for _, asset := range []*Asset{parent, child} {
+		if err := requireNoTransferLock(asset); err != nil {
+			return err
+		}
+		if err := requireNotArchived(asset); err != nil {
+			return err
+		}
+		if err := requireNotFrozen(asset); err != nil {
+			return err
+		}
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+
+	remaining := []string{}
+	for _, id := range parent.Children {
+		if id != childID {
+			remaining = append(remaining, id)
+		}
+	}
+	parent.Children = remaining
+	child.ParentID = ""
+	return s.putBundleLink(ctx, "AssetDetached", parent, child, callerID, timestamp.Seconds)
+}
+
+// putBundleLink stores both sides of an attach/detach and emits the bundle event
+func (s *SmartContract) putBundleLink(ctx contractapi.TransactionContextInterface, eventName string, parent *Asset, child *Asset, actorID string, now int64) error {
+	for _, asset := range []*Asset{parent, child} {
+		asset.UpdatedAt = now
+		asset.LastModifiedBy = actorID
+		asset.Sequence = asset.Sequence + 1
+		if _, err := putAsset(ctx, asset); err != nil {
+			return err
+		}
+	}
+
+	eventJSON, err := json.Marshal(BundleEvent{Parent: parent, Child: child})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
+
+// GetAssetTree returns the whole bundle an asset belongs to, starting from its top-level parent
+func (s *SmartContract) GetAssetTree(ctx contractapi.TransactionContextInterface, assetID string) (*AssetTreeNode, error) {
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := s.ancestorsOf(ctx, asset)
+	if err != nil {
+		return nil, err
+	}
+	root := asset
+	if len(ancestors) > 0 {
+		root, err = s.ReadAsset(ctx, ancestors[len(ancestors)-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.buildAssetTree(ctx, root, 0)
+}
+
+// buildAssetTree reads the subtree below an asset
+func (s *SmartContract) buildAssetTree(ctx contractapi.TransactionContextInterface, asset *Asset, depth int) (*AssetTreeNode, error) {
+	node := &AssetTreeNode{Asset: asset, Children: []*AssetTreeNode{}}
+	if depth >= maxBundleDepth {
+		return node, nil
+	}
+	for _, childID := range asset.Children {
+		child, err := s.ReadAsset(ctx, childID)
+		if err != nil {
+			return nil, err
+		}
+		childNode, err := s.buildAssetTree(ctx, child, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+	return node, nil
+}
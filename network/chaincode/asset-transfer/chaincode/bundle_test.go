@@ -0,0 +1,50 @@
+package chaincode
+
+import "testing"
+
+func TestCheckBundleLink(t *testing.T) {
+	tests := []struct {
+		name        string
+		parentID    string
+		childID     string
+		ancestors   []string
+		childHeight int
+		wantErr     bool
+	}{
+		{"standalone assets", "truck", "trailer", nil, 0, false},
+		{"deepest allowed level", "toolbox", "tool", []string{"trailer", "truck"}, 0, false},
+		{"child subtree fits", "truck", "trailer", nil, 2, false},
+		{"too deep below the parent", "tool", "screw", []string{"toolbox", "trailer", "truck"}, 0, true},
+		{"child subtree too tall", "trailer", "toolbox", []string{"truck"}, 2, true},
+		{"child is the parent's parent", "trailer", "truck", []string{"truck"}, 1, true},
+		{"child is a distant ancestor", "toolbox", "truck", []string{"trailer", "truck"}, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkBundleLink(tt.parentID, tt.childID, tt.ancestors, tt.childHeight)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkBundleLink(%q, %q) error = %v, wantErr %v", tt.parentID, tt.childID, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequireNoBundle(t *testing.T) {
+	tests := []struct {
+		name    string
+		asset   *Asset
+		wantErr bool
+	}{
+		{"standalone", &Asset{ID: "truck"}, false},
+		{"attached child", &Asset{ID: "trailer", ParentID: "truck"}, true},
+		{"parent with children", &Asset{ID: "truck", Children: []string{"trailer"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := requireNoBundle(tt.asset)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("requireNoBundle(%s) error = %v, wantErr %v", tt.asset.ID, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Lending. Custody is kept apart from ownership: the owner lends an asset to a borrower
+// (the Custodian) until a due date, and keeps owning it throughout. A lent asset cannot be
+// transferred, split, bundled or deleted until it is returned by the borrower or recalled by
+// the owner. Once overdue, the System identity may recall it too (the backend loan sweeper).
+
+// maxLoanSeconds is the longest loan period LendAsset accepts (one year)
+const maxLoanSeconds = 365 * 24 * 60 * 60
+
+// CustodyEvent is the payload of AssetLent, AssetReturned and AssetRecalled
+type CustodyEvent struct {
+	Asset     *Asset `json:"asset"`
+	Custodian string `json:"custodian"` // The borrower the asset was lent to or came back from
+	Until     int64  `json:"until"`     // Due date of the loan
+	Actor     string `json:"actor"`
+}
+
+// isOnLoan reports whether the asset is in someone else's custody
+func isOnLoan(asset *Asset) bool {
+	return asset.Custodian != ""
+}
+
+// requireNotOnLoan fails while the asset is lent out
+func requireNotOnLoan(asset *Asset) error {
+	if isOnLoan(asset) {
+		return fmt.Errorf("asset %s is on loan to %s until %d; it must be returned or recalled first", asset.ID, asset.Custodian, asset.CustodyUntil)
+	}
+	return nil
+}
+
+// LendAsset hands custody of an asset to a borrower until the given Unix time.
+// Only the owner of the whole asset may lend it, and ownership does not change.
+func (s *SmartContract) LendAsset(ctx contractapi.TransactionContextInterface, assetID string, borrower string, until int64) error {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if asset.Owner != callerID {
+		return fmt.Errorf("only the owner can lend an asset. Owner: %s, Caller: %s", asset.Owner, callerID)
+	}
+	if err := requireNotOnLoan(asset); err != nil {
+		return err
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return err
+	}
+	if err := requireNoTransferLock(asset); err != nil {
+		return err
+	}
+	if err := requireTransferable(asset); err != nil {
+		return err
+	}
+	if err := requireSoleHolder(asset, callerID); err != nil {
+		return err
+	}
+	if err := requireNotAttached(asset); err != nil {
+		return err
+	}
+
+	if borrower == "" || borrower == callerID {
+		return fmt.Errorf("a borrower other than yourself is required")
+	}
+	if _, err := s.ReadUser(ctx, borrower); err != nil {
+		return err
+	}
+	if err := requireUnlocked(ctx, borrower); err != nil {
+		return err
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+	now := timestamp.Seconds
+	if until <= now || until > now+maxLoanSeconds {
+		return fmt.Errorf("the loan must end in the future and within %d days, got %d", maxLoanSeconds/86400, until)
+	}
+
+	asset.Custodian = borrower
+	asset.CustodySince = now
+	asset.CustodyUntil = until
+	return s.putCustodyChange(ctx, "AssetLent", asset, borrower, until, callerID, now)
+}
+
+// ReturnAsset ends a loan early or on time; only the borrower may return an asset
+func (s *SmartContract) ReturnAsset(ctx contractapi.TransactionContextInterface, assetID string) error {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if !isOnLoan(asset) {
+		return fmt.Errorf("asset %s is not on loan", assetID)
+	}
+	if asset.Custodian != callerID {
+		return fmt.Errorf("only the borrower can return asset %s. Custodian: %s, Caller: %s", assetID, asset.Custodian, callerID)
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return err
+	}
+	return s.endCustody(ctx, "AssetReturned", asset, callerID)
+}
+
+// RecallAsset takes an asset back from its borrower. The owner may recall at any time;
+// the System identity (and admins) only once the loan is overdue.
+func (s *SmartContract) RecallAsset(ctx contractapi.TransactionContextInterface, assetID string) error {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if !isOnLoan(asset) {
+		return fmt.Errorf("asset %s is not on loan", assetID)
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return err
+	}
+
+	if asset.Owner != callerID {
+		if err := requireRole(ctx, RoleSystem, RoleAdmin); err != nil {
+			return fmt.Errorf("only the owner can recall asset %s before it is overdue. Owner: %s, Caller: %s", assetID, asset.Owner, callerID)
+		}
+		timestamp, err := ctx.GetStub().GetTxTimestamp()
+		if err != nil {
+			return err
+		}
+		if timestamp.Seconds <= asset.CustodyUntil {
+			return fmt.Errorf("asset %s is not overdue (due %d)", assetID, asset.CustodyUntil)
+		}
+	}
+	return s.endCustody(ctx, "AssetRecalled", asset, callerID)
+}
+
+// endCustody clears the custody of a lent asset and records who ended it
+func (s *SmartContract) endCustody(ctx contractapi.TransactionContextInterface, eventName string, asset *Asset, actorID string) error {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+
+	custodian, until := asset.Custodian, asset.CustodyUntil
+	asset.Custodian = ""
+	asset.CustodySince = 0
+	asset.CustodyUntil = 0
+	return s.putCustodyChange(ctx, eventName, asset, custodian, until, actorID, timestamp.Seconds)
+}
+
+// putCustodyChange stores the asset and emits the custody event
+func (s *SmartContract) putCustodyChange(ctx contractapi.TransactionContextInterface, eventName string, asset *Asset, custodian string, until int64, actorID string, now int64) error {
+	asset.UpdatedAt = now
+	asset.LastModifiedBy = actorID
+	asset.Sequence = asset.Sequence + 1
+	if _, err := putAsset(ctx, asset); err != nil {
+		return err
+	}
+
+	eventJSON, err := json.Marshal(CustodyEvent{Asset: asset, Custodian: custodian, Until: until, Actor: actorID})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
+
+// GetOverdueLoans returns the lent assets whose due date has passed, most overdue first
+func (s *SmartContract) GetOverdueLoans(ctx contractapi.TransactionContextInterface) ([]*Asset, error) {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetObjectType, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assets: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	overdue := []*Asset{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var asset Asset
+		if err := json.Unmarshal(queryResponse.Value, &asset); err != nil {
+			continue
+		}
+		if isOnLoan(&asset) && timestamp.Seconds > asset.CustodyUntil {
+			overdue = append(overdue, &asset)
+		}
+	}
+	sort.SliceStable(overdue, func(i, j int) bool { return overdue[i].CustodyUntil < overdue[j].CustodyUntil })
+	return overdue, nil
+}
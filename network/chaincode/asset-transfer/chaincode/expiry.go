@@ -0,0 +1,222 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Expiry window applied to asset types without a configured ExpiryWindow (seconds)
+const (
+	defaultExpirySeconds    = 86400      // 24 hours
+	defaultMinExpirySeconds = 3600       // 1 hour
+	defaultMaxExpirySeconds = 30 * 86400 // 30 days
+)
+
+// ExpiryWindow bounds the approval deadline a transfer of the given asset type may ask for
+type ExpiryWindow struct {
+	DocType        string `json:"docType"` // "expiry_window"
+	AssetType      string `json:"assetType"`
+	MinSeconds     int64  `json:"minSeconds"`
+	MaxSeconds     int64  `json:"maxSeconds"`
+	DefaultSeconds int64  `json:"defaultSeconds"` // Used when InitiateTransfer is called with 0
+	UpdatedAt      int64  `json:"updatedAt"`
+	UpdatedBy      string `json:"updatedBy"`
+}
+
+// readExpiryWindow returns the window configured for an asset type, or the built-in default
+func readExpiryWindow(ctx contractapi.TransactionContextInterface, assetType string) (*ExpiryWindow, error) {
+	key, err := expiryWindowKey(ctx, assetType)
+	if err != nil {
+		return nil, err
+	}
+	windowJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if windowJSON == nil {
+		return &ExpiryWindow{
+			DocType:        "expiry_window",
+			AssetType:      assetType,
+			MinSeconds:     defaultMinExpirySeconds,
+			MaxSeconds:     defaultMaxExpirySeconds,
+			DefaultSeconds: defaultExpirySeconds,
+		}, nil
+	}
+
+	var window ExpiryWindow
+	if err := json.Unmarshal(windowJSON, &window); err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+// resolveExpirySeconds picks the approval window for a transfer: the type default when
+// none is requested, otherwise the requested value if it lies within the type's bounds
+func resolveExpirySeconds(window *ExpiryWindow, requested int64) (int64, error) {
+	if requested == 0 {
+		return window.DefaultSeconds, nil
+	}
+	if requested < window.MinSeconds || requested > window.MaxSeconds {
+		return 0, fmt.Errorf("expiry of %ds is outside the window for %s: %ds to %ds",
+			requested, window.AssetType, window.MinSeconds, window.MaxSeconds)
+	}
+	return requested, nil
+}
+
+// SetExpiryWindow configures the min/max/default transfer approval window for an asset type
+func (s *SmartContract) SetExpiryWindow(ctx contractapi.TransactionContextInterface, assetType string, minSeconds int64, maxSeconds int64, defaultSeconds int64) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	adminID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if assetType == "" {
+		return fmt.Errorf("asset type is required")
+	}
+	if minSeconds <= 0 || maxSeconds < minSeconds {
+		return fmt.Errorf("invalid window: need 0 < min (%d) <= max (%d)", minSeconds, maxSeconds)
+	}
+	if defaultSeconds < minSeconds || defaultSeconds > maxSeconds {
+		return fmt.Errorf("default %ds must lie within %ds to %ds", defaultSeconds, minSeconds, maxSeconds)
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+
+	window := ExpiryWindow{
+		DocType:        "expiry_window",
+		AssetType:      assetType,
+		MinSeconds:     minSeconds,
+		MaxSeconds:     maxSeconds,
+		DefaultSeconds: defaultSeconds,
+		UpdatedAt:      timestamp.Seconds,
+		UpdatedBy:      adminID,
+	}
+	key, err := expiryWindowKey(ctx, assetType)
+	if err != nil {
+		return err
+	}
+	windowJSON, err := json.Marshal(window)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(key, windowJSON); err != nil {
+		return fmt.Errorf("failed to store expiry window: %v", err)
+	}
+
+	return ctx.GetStub().SetEvent("ExpiryWindowSet", windowJSON)
+}
+
+// GetExpiryWindow returns the transfer approval window for an asset type (built-in default if not configured)
+func (s *SmartContract) GetExpiryWindow(ctx contractapi.TransactionContextInterface, assetType string) (*ExpiryWindow, error) {
+	return readExpiryWindow(ctx, assetType)
+}
+
+// maxExpiryBatch bounds how many transfers one ExpirePendingTransfers call may close,
+// keeping the write set (transfer + asset per expiry) small enough to endorse reliably
+const maxExpiryBatch = 50
+
+// ExpiryResult is returned by ExpirePendingTransfers and is also the TransfersExpired event payload
+type ExpiryResult struct {
+	Expired   int             `json:"expired"`
+	HasMore   bool            `json:"hasMore"` // More overdue transfers remain; call again
+	Transfers []TransferEvent `json:"transfers"`
+}
+
+// ExpirePendingTransfers marks up to batchSize overdue PENDING transfers as EXPIRED and
+// releases their assets. It is meant to be called on a timer by a system identity.
+// A single TransfersExpired event carries every expired transfer, since Fabric
+// delivers only one event per transaction.
+func (s *SmartContract) ExpirePendingTransfers(ctx contractapi.TransactionContextInterface, batchSize int) (*ExpiryResult, error) {
+	if err := requireRole(ctx, RoleSystem, RoleAdmin); err != nil {
+		return nil, err
+	}
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if batchSize <= 0 || batchSize > maxExpiryBatch {
+		batchSize = maxExpiryBatch
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	now := timestamp.Seconds
+
+	// Collect first, write afterwards: the iterator reads committed state only.
+	// The rich query (indexPendingExpiry) reads overdue PENDING transfers only, so the cost does not
+	// grow with the transfers already closed; the iterator is lazy, so at most one more is read than expired.
+	queryJSON, err := json.Marshal(map[string]interface{}{
+		"selector": map[string]interface{}{
+			"docType":    "pending_transfer",
+			"status":     TransferStatusPending,
+			"expires_at": map[string]interface{}{"$lt": now},
+		},
+		"use_index": []string{"_design/indexPendingExpiryDoc", "indexPendingExpiry"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, err := ctx.GetStub().GetQueryResult(string(queryJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overdue transfers: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	result := &ExpiryResult{Transfers: []TransferEvent{}}
+	var overdue []*PendingTransfer
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var pending PendingTransfer
+		if err := json.Unmarshal(queryResponse.Value, &pending); err != nil {
+			continue
+		}
+		if pending.Status != TransferStatusPending || now <= pending.ExpiresAt {
+			continue
+		}
+		if len(overdue) == batchSize {
+			result.HasMore = true
+			break
+		}
+		overdue = append(overdue, &pending)
+	}
+
+	for _, pending := range overdue {
+		asset, children, err := s.endTransfer(ctx, pending, TransferStatusExpired, callerID, now)
+		if err != nil {
+			return nil, err
+		}
+		if len(children) == 0 {
+			children = nil
+		}
+		result.Transfers = append(result.Transfers, TransferEvent{PendingTransfer: pending, Asset: asset, Children: children})
+	}
+	result.Expired = len(overdue)
+
+	if result.Expired == 0 {
+		return result, nil
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().SetEvent("TransfersExpired", resultJSON); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,33 @@
+package chaincode
+
+import "testing"
+
+func TestResolveExpirySeconds(t *testing.T) {
+	window := &ExpiryWindow{AssetType: "Vehicle", MinSeconds: 3600, MaxSeconds: 7 * 86400, DefaultSeconds: 86400}
+
+	tests := []struct {
+		name      string
+		requested int64
+		want      int64
+		wantErr   bool
+	}{
+		{"zero uses the type default", 0, 86400, false},
+		{"lower bound is inclusive", 3600, 3600, false},
+		{"upper bound is inclusive", 7 * 86400, 7 * 86400, false},
+		{"within bounds", 2 * 86400, 2 * 86400, false},
+		{"below minimum", 3599, 0, true},
+		{"above maximum", 7*86400 + 1, 0, true},
+		{"negative", -60, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveExpirySeconds(window, tt.requested)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveExpirySeconds(%d) error = %v, wantErr %v", tt.requested, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("resolveExpirySeconds(%d) = %d, want %d", tt.requested, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Legal freeze. Admins put an asset under dispute on hold with FreezeAsset, citing a case
+// reference. While frozen, every mutation and transfer of the asset is refused, and a pending
+// transfer on it moves to the FROZEN state: it can be neither approved, rejected, cancelled nor
+// expired. UnfreezeAsset, citing the same case, lifts the hold and resumes the transfer with the
+// approval time it had left. Every freeze and unfreeze is kept in Asset.FreezeHistory.
+
+// Freeze actions
+const (
+	FreezeActionFreeze   = "FREEZE"
+	FreezeActionUnfreeze = "UNFREEZE"
+)
+
+// TransferStatusFrozen marks a pending transfer held by a freeze of its asset
+const TransferStatusFrozen = "FROZEN"
+
+// FreezeRecord is one freeze or unfreeze of an asset
+type FreezeRecord struct {
+	Action    string `json:"action"` // FREEZE or UNFREEZE
+	CaseRef   string `json:"caseRef"`
+	Reason    string `json:"reason"`
+	Actor     string `json:"actor"`
+	Timestamp int64  `json:"timestamp"`
+	TxID      string `json:"txId"`
+}
+
+// AssetFreezeEvent is the payload of AssetFrozen and AssetUnfrozen.
+// Transfer is the pending transfer the change held or resumed, if any.
+type AssetFreezeEvent struct {
+	Asset    *Asset           `json:"asset"`
+	Record   *FreezeRecord    `json:"record"`
+	Transfer *PendingTransfer `json:"transfer,omitempty"`
+}
+
+// isFrozen reports whether the asset is under a legal freeze
+func isFrozen(asset *Asset) bool {
+	return asset.Freeze != nil
+}
+
+// requireNotFrozen fails if the asset is under a legal freeze
+func requireNotFrozen(asset *Asset) error {
+	if isFrozen(asset) {
+		return fmt.Errorf("asset %s is frozen under case %s (%s); an admin must unfreeze it first", asset.ID, asset.Freeze.CaseRef, asset.Freeze.Reason)
+	}
+	return nil
+}
+
+// FreezeAsset puts an asset on legal hold (admin only). A pending transfer on it moves to FROZEN.
+func (s *SmartContract) FreezeAsset(ctx contractapi.TransactionContextInterface, id string, caseRef string, reason string) error {
+	record, asset, err := s.newFreezeRecord(ctx, FreezeActionFreeze, id, caseRef, reason)
+	if err != nil {
+		return err
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return err
+	}
+	// A bundled asset locked by its bundle's transfer cannot hold that transfer itself; the bundle root must be frozen
+	if asset.Status == AssetStatusPendingTransfer && asset.ParentID != "" {
+		return fmt.Errorf("asset %s is locked by the pending transfer of its bundle; freeze the bundle root instead", id)
+	}
+
+	pending, err := s.heldTransfer(ctx, id, TransferStatusPending)
+	if err != nil {
+		return err
+	}
+	if pending != nil {
+		pending.Status = TransferStatusFrozen
+		pending.FrozenAt = record.Timestamp
+		if _, err := putPendingTransfer(ctx, pending); err != nil {
+			return err
+		}
+	}
+
+	asset.Freeze = record
+	return s.putFreezeChange(ctx, "AssetFrozen", asset, record, pending)
+}
+
+// UnfreezeAsset lifts a legal hold (admin only). caseRef must match the case the asset was frozen under.
+// A FROZEN transfer becomes pending again, its deadline pushed back by the time it spent frozen.
+func (s *SmartContract) UnfreezeAsset(ctx contractapi.TransactionContextInterface, id string, caseRef string, reason string) error {
+	record, asset, err := s.newFreezeRecord(ctx, FreezeActionUnfreeze, id, caseRef, reason)
+	if err != nil {
+		return err
+	}
+	if !isFrozen(asset) {
+		return fmt.Errorf("asset %s is not frozen", id)
+	}
+	if asset.Freeze.CaseRef != caseRef {
+		return fmt.Errorf("asset %s is frozen under case %s, not %s", id, asset.Freeze.CaseRef, caseRef)
+	}
+
+	pending, err := s.heldTransfer(ctx, id, TransferStatusFrozen)
+	if err != nil {
+		return err
+	}
+	if pending != nil {
+		pending.Status = TransferStatusPending
+		pending.ExpiresAt = pending.ExpiresAt + (record.Timestamp - pending.FrozenAt)
+		pending.FrozenAt = 0
+		if _, err := putPendingTransfer(ctx, pending); err != nil {
+			return err
+		}
+	} else if asset.Status == AssetStatusPendingTransfer {
+		// The lock of a bundled asset outlives a transfer that ended while it was frozen; lift it now
+		if err := s.releaseStaleBundleLock(ctx, asset); err != nil {
+			return err
+		}
+	}
+
+	asset.Freeze = nil
+	return s.putFreezeChange(ctx, "AssetUnfrozen", asset, record, pending)
+}
+
+// releaseStaleBundleLock restores the status of a bundled asset whose bundle no longer has a pending transfer
+func (s *SmartContract) releaseStaleBundleLock(ctx contractapi.TransactionContextInterface, asset *Asset) error {
+	ancestors, err := s.ancestorsOf(ctx, asset)
+	if err != nil {
+		return err
+	}
+	if len(ancestors) == 0 {
+		return nil
+	}
+	rootPending, err := s.heldTransfer(ctx, ancestors[len(ancestors)-1], TransferStatusPending)
+	if err != nil {
+		return err
+	}
+	if rootPending == nil {
+		restoreStatusAfterTransfer(asset)
+	}
+	return nil
+}
+
+// GetFreezeHistory returns every freeze and unfreeze of an asset, oldest first
+func (s *SmartContract) GetFreezeHistory(ctx contractapi.TransactionContextInterface, id string) ([]*FreezeRecord, error) {
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if asset.FreezeHistory == nil {
+		return []*FreezeRecord{}, nil
+	}
+	return asset.FreezeHistory, nil
+}
+
+// newFreezeRecord checks the caller and arguments of a freeze change and reads the asset
+func (s *SmartContract) newFreezeRecord(ctx contractapi.TransactionContextInterface, action string, id string, caseRef string, reason string) (*FreezeRecord, *Asset, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, nil, err
+	}
+	adminID, err := activeCallerID(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if strings.TrimSpace(caseRef) == "" || strings.TrimSpace(reason) == "" {
+		return nil, nil, fmt.Errorf("a case reference and a reason are required")
+	}
+
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	record := &FreezeRecord{
+		Action:    action,
+		CaseRef:   caseRef,
+		Reason:    reason,
+		Actor:     adminID,
+		Timestamp: timestamp.Seconds,
+		TxID:      ctx.GetStub().GetTxID(),
+	}
+	return record, asset, nil
+}
+
+// heldTransfer returns the transfer on an asset if it is in the given status, or nil
+func (s *SmartContract) heldTransfer(ctx contractapi.TransactionContextInterface, assetID string, status string) (*PendingTransfer, error) {
+	key, err := transferKey(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	pendingBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending transfer: %v", err)
+	}
+	if pendingBytes == nil {
+		return nil, nil
+	}
+
+	var pending PendingTransfer
+	if err := json.Unmarshal(pendingBytes, &pending); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pending transfer: %v", err)
+	}
+	if pending.Status != status {
+		return nil, nil
+	}
+	return &pending, nil
+}
+
+// putFreezeChange records the change in the freeze history, stores the asset and emits the event
+func (s *SmartContract) putFreezeChange(ctx contractapi.TransactionContextInterface, eventName string, asset *Asset, record *FreezeRecord, pending *PendingTransfer) error {
+	asset.FreezeHistory = append(asset.FreezeHistory, record)
+	asset.UpdatedAt = record.Timestamp
+	asset.LastModifiedBy = record.Actor
+	asset.Sequence = asset.Sequence + 1
+
+	if _, err := putAsset(ctx, asset); err != nil {
+		return err
+	}
+
+	eventJSON, err := json.Marshal(AssetFreezeEvent{Asset: asset, Record: record, Transfer: pending})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
@@ -0,0 +1,98 @@
+package chaincode
+
+import "testing"
+
+func TestFreezeAssetAuthorization(t *testing.T) {
+	tests := []struct {
+		name       string
+		callerID   string
+		callerRole string
+		wantErr    bool
+	}{
+		{"owner", "alice", RoleUser, true},
+		{"auditor", "auditor", RoleAuditor, true},
+		{"no role attribute", "alice", "", true},
+		{"admin", "admin", RoleAdmin, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := newTestLedger(t)
+			ledger.seedUsers(map[string]string{"alice": RoleUser})
+			ledger.seedAsset(&Asset{ID: "asset1", Owner: "alice", Status: AssetStatusOwned, Sequence: 1})
+
+			err := (&SmartContract{}).FreezeAsset(ledger.as(tt.callerID, tt.callerRole), "asset1", "CASE-1", "ownership dispute")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FreezeAsset() as %s error = %v, wantErr %v", tt.callerID, err, tt.wantErr)
+			}
+			if frozen := isFrozen(ledger.asset("asset1")); frozen == tt.wantErr {
+				t.Fatalf("frozen = %v, wantErr %v", frozen, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFreezeAssetBlocksChanges(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.seedUsers(map[string]string{"alice": RoleUser, "bob": RoleUser})
+	ledger.seedAsset(&Asset{ID: "asset1", Name: "Car", Type: "Vehicle", Owner: "alice", Status: AssetStatusOwned, Sequence: 1})
+	contract := &SmartContract{}
+
+	if err := contract.FreezeAsset(ledger.as("admin", RoleAdmin), "asset1", "CASE-1", "ownership dispute"); err != nil {
+		t.Fatalf("FreezeAsset: %v", err)
+	}
+	if err := contract.FreezeAsset(ledger.as("admin", RoleAdmin), "asset1", "CASE-2", "second case"); err == nil {
+		t.Fatal("a frozen asset was frozen again")
+	}
+	if _, err := contract.InitiateTransfer(ledger.as("alice", RoleUser), "asset1", "bob", 0, 0); err == nil {
+		t.Fatal("a transfer was initiated on a frozen asset")
+	}
+	if err := contract.UpdateAsset(ledger.as("alice", RoleUser), "asset1", "Renamed", "Vehicle", "alice", AssetStatusOwned, "", "", ""); err == nil {
+		t.Fatal("a frozen asset was updated")
+	}
+	if err := contract.UnfreezeAsset(ledger.as("alice", RoleUser), "asset1", "CASE-1", "settled"); err == nil {
+		t.Fatal("the owner unfroze the asset")
+	}
+}
+
+func TestFreezeAssetHoldsPendingTransfer(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.seedUsers(map[string]string{"alice": RoleUser, "bob": RoleUser})
+	ledger.seedAsset(&Asset{ID: "asset1", Name: "Car", Type: "Vehicle", Owner: "alice", Status: AssetStatusOwned, Sequence: 1})
+	contract := &SmartContract{}
+
+	if _, err := contract.InitiateTransfer(ledger.as("alice", RoleUser), "asset1", "bob", 0, 0); err != nil {
+		t.Fatalf("InitiateTransfer: %v", err)
+	}
+	if err := contract.FreezeAsset(ledger.as("admin", RoleAdmin), "asset1", "CASE-1", "ownership dispute"); err != nil {
+		t.Fatalf("FreezeAsset: %v", err)
+	}
+	pending, err := contract.GetPendingTransfer(ledger.as("alice", RoleUser), "asset1")
+	if err != nil {
+		t.Fatalf("GetPendingTransfer: %v", err)
+	}
+	if pending.Status != TransferStatusFrozen {
+		t.Fatalf("transfer status = %q, want %q", pending.Status, TransferStatusFrozen)
+	}
+
+	// The held transfer can be neither approved nor rejected, and only the freezing case lifts the hold
+	if _, err := contract.ApproveTransfer(ledger.as("bob", RoleUser), "asset1"); err == nil {
+		t.Fatal("a frozen transfer was approved")
+	}
+	if err := contract.RejectTransfer(ledger.as("bob", RoleUser), "asset1", "changed my mind"); err == nil {
+		t.Fatal("a frozen transfer was rejected")
+	}
+	if err := contract.UnfreezeAsset(ledger.as("admin", RoleAdmin), "asset1", "CASE-2", "wrong case"); err == nil {
+		t.Fatal("the asset was unfrozen under another case")
+	}
+	if err := contract.UnfreezeAsset(ledger.as("admin", RoleAdmin), "asset1", "CASE-1", "settled"); err != nil {
+		t.Fatalf("UnfreezeAsset: %v", err)
+	}
+
+	pending, err = contract.ApproveTransfer(ledger.as("bob", RoleUser), "asset1")
+	if err != nil {
+		t.Fatalf("ApproveTransfer after unfreeze: %v", err)
+	}
+	if got := ledger.asset("asset1"); pending.Status != TransferStatusExecuted || got.Owner != "bob" {
+		t.Fatalf("after unfreeze: transfer %q, owner %q, want %q and bob", pending.Status, got.Owner, TransferStatusExecuted)
+	}
+}
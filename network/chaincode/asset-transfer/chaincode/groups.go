@@ -0,0 +1,231 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Viewer groups. A group is a named, admin-managed list of users that can be granted
+// view access as a whole with the viewer ID "group:<name>" (see access.go).
+
+// Group is a named set of users kept on the ledger
+type Group struct {
+	DocType   string   `json:"docType"` // "group"
+	ID        string   `json:"id"`
+	Members   []string `json:"members"` // Sorted user IDs
+	UpdatedAt int64    `json:"updatedAt"`
+	UpdatedBy string   `json:"updatedBy"`
+	Sequence  uint64   `json:"sequence"`
+}
+
+// readGroup returns the group, or nil when it does not exist
+func readGroup(ctx contractapi.TransactionContextInterface, groupID string) (*Group, error) {
+	key, err := groupKey(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	groupJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if groupJSON == nil {
+		return nil, nil
+	}
+
+	var group Group
+	if err := json.Unmarshal(groupJSON, &group); err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// groupsOf returns the sorted IDs of the groups the user is a member of
+func groupsOf(ctx contractapi.TransactionContextInterface, userID string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(groupObjectType, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	groups := []string{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var group Group
+		if err := json.Unmarshal(queryResponse.Value, &group); err != nil {
+			continue
+		}
+		for _, member := range group.Members {
+			if member == userID {
+				groups = append(groups, group.ID)
+				break
+			}
+		}
+	}
+	sort.Strings(groups)
+	return groups, nil
+}
+
+// saveGroup stamps and stores a group after checking that every member is a registered user
+func (s *SmartContract) saveGroup(ctx contractapi.TransactionContextInterface, group *Group) error {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	members := []string{}
+	seen := map[string]bool{}
+	for _, member := range group.Members {
+		if seen[member] {
+			continue
+		}
+		if _, err := s.ReadUser(ctx, member); err != nil {
+			return err
+		}
+		seen[member] = true
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+
+	group.DocType = "group"
+	group.Members = members
+	group.UpdatedAt = timestamp.Seconds
+	group.UpdatedBy = callerID
+	group.Sequence = group.Sequence + 1
+
+	groupJSON, err := putGroup(ctx, group)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent("GroupUpdated", groupJSON)
+}
+
+// SetGroupMembers creates a group or replaces its members. membersJSON is an array of user IDs.
+func (s *SmartContract) SetGroupMembers(ctx contractapi.TransactionContextInterface, groupID string, membersJSON string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if groupID == "" {
+		return fmt.Errorf("a group ID is required")
+	}
+
+	var members []string
+	if err := json.Unmarshal([]byte(membersJSON), &members); err != nil {
+		return fmt.Errorf("invalid members JSON: %v", err)
+	}
+
+	group, err := readGroup(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		group = &Group{ID: groupID}
+	}
+	group.Members = members
+	return s.saveGroup(ctx, group)
+}
+
+// AddGroupMember adds a user to an existing group
+func (s *SmartContract) AddGroupMember(ctx contractapi.TransactionContextInterface, groupID string, userID string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	group, err := s.ReadGroup(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	group.Members = append(group.Members, userID)
+	return s.saveGroup(ctx, group)
+}
+
+// RemoveGroupMember removes a user from a group
+func (s *SmartContract) RemoveGroupMember(ctx contractapi.TransactionContextInterface, groupID string, userID string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	group, err := s.ReadGroup(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	members := []string{}
+	for _, member := range group.Members {
+		if member != userID {
+			members = append(members, member)
+		}
+	}
+	if len(members) == len(group.Members) {
+		return fmt.Errorf("%s is not a member of group %s", userID, groupID)
+	}
+	group.Members = members
+	return s.saveGroup(ctx, group)
+}
+
+// DeleteGroup removes a group. Assets may keep a "group:<name>" viewer entry, which then matches nobody.
+func (s *SmartContract) DeleteGroup(ctx contractapi.TransactionContextInterface, groupID string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if _, err := activeCallerID(ctx); err != nil {
+		return err
+	}
+	if _, err := s.ReadGroup(ctx, groupID); err != nil {
+		return err
+	}
+
+	key, err := groupKey(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return fmt.Errorf("failed to delete group %s: %v", groupID, err)
+	}
+	return ctx.GetStub().SetEvent("GroupDeleted", []byte(groupID))
+}
+
+// ReadGroup returns the group stored in the world state with the given ID
+func (s *SmartContract) ReadGroup(ctx contractapi.TransactionContextInterface, groupID string) (*Group, error) {
+	group, err := readGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, fmt.Errorf("the group %s does not exist", groupID)
+	}
+	return group, nil
+}
+
+// GetAllGroups returns every viewer group
+func (s *SmartContract) GetAllGroups(ctx contractapi.TransactionContextInterface) ([]*Group, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(groupObjectType, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	groups := []*Group{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var group Group
+		if err := json.Unmarshal(queryResponse.Value, &group); err != nil {
+			return nil, err
+		}
+		groups = append(groups, &group)
+	}
+	return groups, nil
+}
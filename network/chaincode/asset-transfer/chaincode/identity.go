@@ -0,0 +1,113 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// getCallerID returns the enrollment ID of the submitting client.
+// It is read from the Common Name of the signing X.509 certificate rather than
+// string-parsing GetID(), which is base64 encoded and includes the issuer DN.
+func getCallerID(ctx contractapi.TransactionContextInterface) (string, error) {
+	cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return "", fmt.Errorf("failed to read client certificate: %v", err)
+	}
+	if cert == nil {
+		return "", fmt.Errorf("client identity has no X.509 certificate")
+	}
+
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+
+	callerID := strings.TrimSpace(cert.Subject.CommonName)
+	// cryptogen identities carry their own org's domain (e.g. User1@org1.example.com for Org1MSP)
+	// and map to the bare name. Any other "@" is part of the ID, so a CA-enrolled "admin@x" never acts as "admin".
+	if at := strings.Index(callerID, "@"); at > 0 && isOrgDomain(callerID[at+1:], mspID) {
+		callerID = callerID[:at]
+	}
+	if callerID == "" {
+		return "", fmt.Errorf("client certificate has no common name")
+	}
+
+	return callerID, nil
+}
+
+// isOrgDomain reports whether domain is the cryptogen domain of the MSP, i.e. org1.example.com for Org1MSP
+func isOrgDomain(domain string, mspID string) bool {
+	org := strings.SplitN(domain, ".", 2)[0]
+	return org != "" && strings.EqualFold(org+"MSP", mspID)
+}
+
+// assertCaller returns the caller ID and rejects the call when a caller-supplied
+// ID does not match the identity in the client certificate.
+func assertCaller(ctx contractapi.TransactionContextInterface, suppliedID string) (string, error) {
+	callerID, err := getCallerID(ctx)
+	if err != nil {
+		return "", err
+	}
+	if suppliedID != "" && suppliedID != callerID {
+		return "", fmt.Errorf("identity mismatch: supplied %s but certificate belongs to %s", suppliedID, callerID)
+	}
+	return callerID, nil
+}
+
+// User statuses
+const (
+	UserStatusActive = "Active"
+	UserStatusLocked = "Locked"
+)
+
+// ErrCodeUserLocked prefixes UserLockedError messages so off-chain clients can detect it
+const ErrCodeUserLocked = "USER_LOCKED"
+
+// UserLockedError is returned when a locked user tries to act on the ledger or receive an asset
+type UserLockedError struct {
+	UserID string
+}
+
+func (e *UserLockedError) Error() string {
+	return fmt.Sprintf("%s: user %s is locked", ErrCodeUserLocked, e.UserID)
+}
+
+// requireUnlocked fails with a UserLockedError when the user exists on the ledger and is locked.
+// Identities without a User record (e.g. system identities) are not restricted.
+func requireUnlocked(ctx contractapi.TransactionContextInterface, userID string) error {
+	key, err := userKey(ctx, userID)
+	if err != nil {
+		return err
+	}
+	userJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if userJSON == nil {
+		return nil
+	}
+
+	var user User
+	if err := json.Unmarshal(userJSON, &user); err != nil {
+		return err
+	}
+	if user.Status == UserStatusLocked {
+		return &UserLockedError{UserID: userID}
+	}
+	return nil
+}
+
+// activeCallerID returns the caller ID, refusing callers whose User record is locked
+func activeCallerID(ctx contractapi.TransactionContextInterface) (string, error) {
+	callerID, err := getCallerID(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err := requireUnlocked(ctx, callerID); err != nil {
+		return "", err
+	}
+	return callerID, nil
+}
@@ -0,0 +1,257 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Object types used to build composite keys. Every document type lives in its
+// own namespace so IDs can never collide across types (e.g. asset "admin" vs user "admin").
+const (
+	assetObjectType     = "asset~id"
+	userObjectType      = "user~id"
+	transferObjectType  = "transfer~assetId"
+	policyObjectType    = "policy~scope~id"
+	expiryObjectType    = "expiry~assetType"
+	groupObjectType     = "group~id"
+	balanceObjectType   = "balance~account"
+	assetTypeObjectType = "assettype~name"
+)
+
+// legacyTransferPrefix is the key prefix used for pending transfers before composite keys
+const legacyTransferPrefix = "PENDING_TRANSFER_"
+
+// assetKey returns the world state key for an asset
+func assetKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(assetObjectType, []string{id})
+}
+
+// userKey returns the world state key for a user
+func userKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(userObjectType, []string{id})
+}
+
+// transferKey returns the world state key for the pending transfer of an asset
+func transferKey(ctx contractapi.TransactionContextInterface, assetID string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(transferObjectType, []string{assetID})
+}
+
+// policyKey returns the world state key for a transfer policy of the given scope
+func policyKey(ctx contractapi.TransactionContextInterface, scope string, scopeID string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(policyObjectType, []string{scope, scopeID})
+}
+
+// expiryWindowKey returns the world state key for the transfer expiry window of an asset type
+func expiryWindowKey(ctx contractapi.TransactionContextInterface, assetType string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(expiryObjectType, []string{assetType})
+}
+
+// groupKey returns the world state key for a viewer group
+func groupKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(groupObjectType, []string{id})
+}
+
+// balanceKey returns the world state key for the token balance of an account
+func balanceKey(ctx contractapi.TransactionContextInterface, account string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(balanceObjectType, []string{account})
+}
+
+// assetTypeKey returns the world state key for a registered asset type
+func assetTypeKey(ctx contractapi.TransactionContextInterface, name string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(assetTypeObjectType, []string{name})
+}
+
+// putAsset writes the asset under its composite key and returns the JSON that was stored
+func putAsset(ctx contractapi.TransactionContextInterface, asset *Asset) ([]byte, error) {
+	asset.Holders = holdersOf(asset)
+	key, err := assetKey(ctx, asset.ID)
+	if err != nil {
+		return nil, err
+	}
+	assetJSON, err := json.Marshal(asset)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutState(key, assetJSON); err != nil {
+		return nil, fmt.Errorf("failed to put asset %s to world state: %v", asset.ID, err)
+	}
+	return assetJSON, nil
+}
+
+// putUser writes the user under its composite key and returns the JSON that was stored
+func putUser(ctx contractapi.TransactionContextInterface, user *User) ([]byte, error) {
+	key, err := userKey(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	userJSON, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutState(key, userJSON); err != nil {
+		return nil, fmt.Errorf("failed to put user %s to world state: %v", user.ID, err)
+	}
+	return userJSON, nil
+}
+
+// putGroup writes the group under its composite key and returns the JSON that was stored
+func putGroup(ctx contractapi.TransactionContextInterface, group *Group) ([]byte, error) {
+	key, err := groupKey(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	groupJSON, err := json.Marshal(group)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutState(key, groupJSON); err != nil {
+		return nil, fmt.Errorf("failed to put group %s to world state: %v", group.ID, err)
+	}
+	return groupJSON, nil
+}
+
+// putBalance writes the token balance under its composite key
+func putBalance(ctx contractapi.TransactionContextInterface, balance *TokenBalance) error {
+	key, err := balanceKey(ctx, balance.Account)
+	if err != nil {
+		return err
+	}
+	balanceJSON, err := json.Marshal(balance)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(key, balanceJSON); err != nil {
+		return fmt.Errorf("failed to put balance of %s to world state: %v", balance.Account, err)
+	}
+	return nil
+}
+
+// putAssetType writes the asset type definition under its composite key and returns the JSON that was stored
+func putAssetType(ctx contractapi.TransactionContextInterface, def *AssetTypeDef) ([]byte, error) {
+	key, err := assetTypeKey(ctx, def.Name)
+	if err != nil {
+		return nil, err
+	}
+	defJSON, err := json.Marshal(def)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutState(key, defJSON); err != nil {
+		return nil, fmt.Errorf("failed to put asset type %s to world state: %v", def.Name, err)
+	}
+	return defJSON, nil
+}
+
+// putPendingTransfer writes the pending transfer under its composite key and returns the JSON that was stored
+func putPendingTransfer(ctx contractapi.TransactionContextInterface, pending *PendingTransfer) ([]byte, error) {
+	key, err := transferKey(ctx, pending.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	pendingJSON, err := json.Marshal(pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal pending transfer: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, pendingJSON); err != nil {
+		return nil, fmt.Errorf("failed to store pending transfer: %v", err)
+	}
+	return pendingJSON, nil
+}
+
+// maxMigrationBatch bounds how many legacy entries one MigrateLegacyKeys call may move,
+// keeping the write set (put + delete per entry) small enough to endorse reliably
+const maxMigrationBatch = 100
+
+// MigrationResult summarises a key migration run
+type MigrationResult struct {
+	Assets    int    `json:"assets"`
+	Users     int    `json:"users"`
+	Transfers int    `json:"transfers"`
+	Skipped   int    `json:"skipped"`
+	Bookmark  string `json:"bookmark"` // Pass back to migrate the next batch; empty when done
+}
+
+// MigrateLegacyKeys rewrites up to batchSize records stored under raw IDs (pre composite-key layout)
+// into their namespaced composite keys and removes the old entries, starting at bookmark.
+// Paginated range queries are read-only in Fabric, so the batch is bounded here and the
+// bookmark is the first legacy key not yet visited. Call again with it until it comes back empty.
+// It is idempotent: once every record has moved, a further run migrates nothing.
+func (s *SmartContract) MigrateLegacyKeys(ctx contractapi.TransactionContextInterface, batchSize int, bookmark string) (*MigrationResult, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := activeCallerID(ctx); err != nil {
+		return nil, err
+	}
+	if batchSize <= 0 || batchSize > maxMigrationBatch {
+		batchSize = maxMigrationBatch
+	}
+
+	// Range queries never return composite keys, so this only sees legacy entries
+	resultsIterator, err := ctx.GetStub().GetStateByRange(bookmark, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	result := &MigrationResult{}
+	visited := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if visited == batchSize {
+			result.Bookmark = queryResponse.Key
+			break
+		}
+		visited++
+
+		var doc struct {
+			DocType string `json:"docType"`
+		}
+		if err := json.Unmarshal(queryResponse.Value, &doc); err != nil {
+			result.Skipped++
+			continue
+		}
+
+		var newKey string
+		switch {
+		case strings.HasPrefix(queryResponse.Key, legacyTransferPrefix) || doc.DocType == "pending_transfer":
+			newKey, err = transferKey(ctx, strings.TrimPrefix(queryResponse.Key, legacyTransferPrefix))
+			result.Transfers++
+		case doc.DocType == "asset":
+			newKey, err = assetKey(ctx, queryResponse.Key)
+			result.Assets++
+		case doc.DocType == "user":
+			newKey, err = userKey(ctx, queryResponse.Key)
+			result.Users++
+		default:
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := ctx.GetStub().PutState(newKey, queryResponse.Value); err != nil {
+			return nil, fmt.Errorf("failed to migrate key %s: %v", queryResponse.Key, err)
+		}
+		if err := ctx.GetStub().DelState(queryResponse.Key); err != nil {
+			return nil, fmt.Errorf("failed to delete legacy key %s: %v", queryResponse.Key, err)
+		}
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().SetEvent("LedgerMigrated", resultJSON); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
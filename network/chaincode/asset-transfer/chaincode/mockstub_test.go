@@ -0,0 +1,94 @@
+package chaincode
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// testIdentity is a client identity enrolled in Org1MSP, optionally carrying a CA role attribute
+type testIdentity struct {
+	id   string
+	role string
+}
+
+func (i *testIdentity) GetID() (string, error) {
+	return "x509::CN=" + i.id, nil
+}
+
+func (i *testIdentity) GetMSPID() (string, error) {
+	return "Org1MSP", nil
+}
+
+func (i *testIdentity) GetAttributeValue(attrName string) (string, bool, error) {
+	if attrName == roleAttribute && i.role != "" {
+		return i.role, true, nil
+	}
+	return "", false, nil
+}
+
+func (i *testIdentity) AssertAttributeValue(attrName, attrValue string) error {
+	value, found, _ := i.GetAttributeValue(attrName)
+	if !found || value != attrValue {
+		return fmt.Errorf("attribute %s is not %s", attrName, attrValue)
+	}
+	return nil
+}
+
+func (i *testIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return &x509.Certificate{Subject: pkix.Name{CommonName: i.id}}, nil
+}
+
+// testLedger drives transaction functions against an in-memory MockStub.
+// MockStub applies writes immediately, so a failed call may leave partial state behind;
+// tests assert on calls whose guards reject them before anything is written.
+type testLedger struct {
+	t    *testing.T
+	stub *shimtest.MockStub
+	txs  int
+}
+
+func newTestLedger(t *testing.T) *testLedger {
+	return &testLedger{t: t, stub: shimtest.NewMockStub("basic", nil)}
+}
+
+// as starts a new transaction submitted by the given user and role ("" for no role attribute)
+func (l *testLedger) as(id string, role string) contractapi.TransactionContextInterface {
+	l.txs++
+	l.stub.MockTransactionStart(fmt.Sprintf("tx%d", l.txs))
+	ctx := &contractapi.TransactionContext{}
+	ctx.SetStub(l.stub)
+	ctx.SetClientIdentity(&testIdentity{id: id, role: role})
+	return ctx
+}
+
+// seedUsers stores active users with the given roles, keyed by ID
+func (l *testLedger) seedUsers(roles map[string]string) {
+	ctx := l.as("system", RoleSystem)
+	for id, role := range roles {
+		if _, err := putUser(ctx, &User{DocType: "user", ID: id, Role: role, Status: UserStatusActive, Sequence: 1}); err != nil {
+			l.t.Fatalf("seeding user %s: %v", id, err)
+		}
+	}
+}
+
+// seedAsset stores an asset as-is, filling in the document type
+func (l *testLedger) seedAsset(asset *Asset) {
+	asset.DocType = "asset"
+	if _, err := putAsset(l.as("system", RoleSystem), asset); err != nil {
+		l.t.Fatalf("seeding asset %s: %v", asset.ID, err)
+	}
+}
+
+// asset reads an asset back from the ledger
+func (l *testLedger) asset(id string) *Asset {
+	asset, err := (&SmartContract{}).ReadAsset(l.as("system", RoleSystem), id)
+	if err != nil {
+		l.t.Fatalf("reading asset %s: %v", id, err)
+	}
+	return asset
+}
@@ -0,0 +1,108 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// maxPageSize caps the page size clients may request from the paginated queries
+const maxPageSize = 200
+
+// AssetPage is one page of assets plus the bookmark to fetch the next one
+type AssetPage struct {
+	Records             []*Asset `json:"records"`
+	FetchedRecordsCount int32    `json:"fetchedRecordsCount"`
+	Bookmark            string   `json:"bookmark"` // Pass back to get the next page; empty when done
+}
+
+// TransferPage is one page of pending transfers plus the bookmark to fetch the next one.
+// Records only holds PENDING transfers, so it can be shorter than FetchedRecordsCount.
+type TransferPage struct {
+	Records             []*PendingTransfer `json:"records"`
+	FetchedRecordsCount int32              `json:"fetchedRecordsCount"`
+	Bookmark            string             `json:"bookmark"`
+}
+
+// validatePageSize rejects page sizes outside 1..maxPageSize
+func validatePageSize(pageSize int32) error {
+	if pageSize <= 0 || pageSize > maxPageSize {
+		return fmt.Errorf("page size must be between 1 and %d, got %d", maxPageSize, pageSize)
+	}
+	return nil
+}
+
+// GetAssetsWithPagination returns one page of assets. Pagination queries are read-only,
+// so this can only be evaluated, not submitted.
+func (s *SmartContract) GetAssetsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*AssetPage, error) {
+	if err := validatePageSize(pageSize); err != nil {
+		return nil, err
+	}
+
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(assetObjectType, []string{}, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	page := &AssetPage{Records: []*Asset{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var asset Asset
+		if err := json.Unmarshal(queryResponse.Value, &asset); err != nil {
+			return nil, err
+		}
+		page.Records = append(page.Records, &asset)
+	}
+	page.FetchedRecordsCount = metadata.FetchedRecordsCount
+	page.Bookmark = nextBookmark(metadata.FetchedRecordsCount, pageSize, metadata.Bookmark)
+
+	return page, nil
+}
+
+// GetPendingTransfersWithPagination returns one page of PENDING transfers
+func (s *SmartContract) GetPendingTransfersWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*TransferPage, error) {
+	if err := validatePageSize(pageSize); err != nil {
+		return nil, err
+	}
+
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(transferObjectType, []string{}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending transfers: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	page := &TransferPage{Records: []*PendingTransfer{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var pending PendingTransfer
+		if err := json.Unmarshal(queryResponse.Value, &pending); err != nil {
+			continue
+		}
+		if pending.Status == TransferStatusPending {
+			page.Records = append(page.Records, &pending)
+		}
+	}
+	page.FetchedRecordsCount = metadata.FetchedRecordsCount
+	page.Bookmark = nextBookmark(metadata.FetchedRecordsCount, pageSize, metadata.Bookmark)
+
+	return page, nil
+}
+
+// nextBookmark normalises the end of a result set to an empty bookmark.
+// CouchDB keeps returning a bookmark after the last page, LevelDB does not.
+func nextBookmark(fetched int32, pageSize int32, bookmark string) string {
+	if fetched < pageSize {
+		return ""
+	}
+	return bookmark
+}
@@ -0,0 +1,316 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Transfer policy scopes. An asset policy overrides the policy of its asset type.
+const (
+	PolicyScopeAssetType = "type"
+	PolicyScopeAsset     = "asset"
+)
+
+// Approval roles recorded on PendingTransfer.Approvals
+const (
+	ApprovalRoleCurrentOwner = "CURRENT_OWNER"
+	ApprovalRoleNewOwner     = "NEW_OWNER"
+	ApprovalRoleApprover     = "APPROVER" // Named co-signer from ApproverUsers
+	approvalRolePrefix       = "ROLE:"    // Holder of one of ApproverRoles, e.g. ROLE:Auditor
+	defaultTransferThreshold = 2          // Current owner + recipient
+)
+
+// TransferPolicy is an M-of-N approval rule for transfers.
+// The current owner and the recipient always have to sign; ApproverUsers and ApproverRoles
+// add further signing slots (one per user, one per role) and Threshold is the number of
+// slots, owner and recipient included, that must be filled before the transfer executes.
+type TransferPolicy struct {
+	DocType       string   `json:"docType"` // "transfer_policy"
+	Scope         string   `json:"scope"`   // "type" or "asset"
+	ScopeID       string   `json:"scopeId"` // Asset type (e.g. "RealEstate") or asset ID
+	Threshold     int      `json:"threshold"`
+	ApproverRoles []string `json:"approverRoles"` // CA roles whose holders may co-sign (e.g. Auditor, Escrow)
+	ApproverUsers []string `json:"approverUsers"` // Named co-signers (e.g. an escrow agent)
+	UpdatedAt     int64    `json:"updatedAt"`
+	UpdatedBy     string   `json:"updatedBy"`
+}
+
+// defaultTransferPolicy is the 2-of-2 owner + recipient rule used when no policy is stored
+func defaultTransferPolicy() *TransferPolicy {
+	return &TransferPolicy{
+		DocType:       "transfer_policy",
+		Threshold:     defaultTransferThreshold,
+		ApproverRoles: []string{},
+		ApproverUsers: []string{},
+	}
+}
+
+// slots returns N, the number of distinct approvals the policy can collect
+func (p *TransferPolicy) slots() int {
+	return defaultTransferThreshold + len(p.ApproverRoles) + len(p.ApproverUsers)
+}
+
+// readTransferPolicy returns the stored policy for a scope, or nil if there is none
+func readTransferPolicy(ctx contractapi.TransactionContextInterface, scope string, scopeID string) (*TransferPolicy, error) {
+	key, err := policyKey(ctx, scope, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	policyJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if policyJSON == nil {
+		return nil, nil
+	}
+
+	var policy TransferPolicy
+	if err := json.Unmarshal(policyJSON, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// effectiveTransferPolicy resolves the policy for an asset: asset policy, then asset type policy, then the default
+func effectiveTransferPolicy(ctx contractapi.TransactionContextInterface, asset *Asset) (*TransferPolicy, error) {
+	policy, err := readTransferPolicy(ctx, PolicyScopeAsset, asset.ID)
+	if err != nil || policy != nil {
+		return policy, err
+	}
+	policy, err = readTransferPolicy(ctx, PolicyScopeAssetType, asset.Type)
+	if err != nil || policy != nil {
+		return policy, err
+	}
+	return defaultTransferPolicy(), nil
+}
+
+// transferPolicyOf returns the policy a pending transfer was initiated under.
+// Transfers created before policies existed follow the default 2-of-2 rule.
+func transferPolicyOf(pending *PendingTransfer) *TransferPolicy {
+	if pending.Policy == nil {
+		return defaultTransferPolicy()
+	}
+	// Snapshots taken before policyForTransfer existed may still name the owner or recipient as approvers
+	return policyForTransfer(pending.Policy, pending.CurrentOwner, pending.NewOwner)
+}
+
+// policyForTransfer returns a copy of the policy fitted to one transfer. The owner and the recipient
+// already sign in their own slots, so they are dropped from ApproverUsers, and the threshold is capped
+// at the slots left; otherwise the transfer could never collect enough approvals and would only expire.
+func policyForTransfer(policy *TransferPolicy, currentOwner string, newOwner string) *TransferPolicy {
+	fitted := *policy
+	fitted.ApproverUsers = []string{}
+	for _, user := range policy.ApproverUsers {
+		if user != currentOwner && user != newOwner {
+			fitted.ApproverUsers = append(fitted.ApproverUsers, user)
+		}
+	}
+	if fitted.Threshold > fitted.slots() {
+		fitted.Threshold = fitted.slots()
+	}
+	return &fitted
+}
+
+// approvalRoleFor works out which signing slot the caller fills on a pending transfer.
+// It fails if the caller is not an approver under the transfer's policy or their slot is already filled.
+func approvalRoleFor(ctx contractapi.TransactionContextInterface, pending *PendingTransfer, callerID string) (string, error) {
+	for _, approval := range pending.Approvals {
+		if approval.Signer == callerID {
+			return "", fmt.Errorf("you have already approved this transfer")
+		}
+	}
+
+	if callerID == pending.NewOwner {
+		return ApprovalRoleNewOwner, nil
+	}
+
+	policy := transferPolicyOf(pending)
+	for _, user := range policy.ApproverUsers {
+		if user == callerID {
+			return ApprovalRoleApprover, nil
+		}
+	}
+
+	filled := map[string]bool{}
+	for _, approval := range pending.Approvals {
+		filled[approval.Role] = true
+	}
+	heldRoleFilled := false
+	for _, role := range policy.ApproverRoles {
+		if ctx.GetClientIdentity().AssertAttributeValue(roleAttribute, role) != nil {
+			continue
+		}
+		if filled[approvalRolePrefix+role] {
+			heldRoleFilled = true
+			continue
+		}
+		return approvalRolePrefix + role, nil
+	}
+	if heldRoleFilled {
+		return "", fmt.Errorf("the approval slot for your role has already been filled")
+	}
+
+	return "", fmt.Errorf("%s is not an approver for this transfer. Recipient: %s, approver users: %v, approver roles: %v",
+		callerID, pending.NewOwner, policy.ApproverUsers, policy.ApproverRoles)
+}
+
+// transferPolicySatisfied reports whether a pending transfer may execute:
+// the recipient has signed and the policy threshold is reached
+func transferPolicySatisfied(pending *PendingTransfer) bool {
+	recipientSigned := false
+	for _, approval := range pending.Approvals {
+		if approval.Role == ApprovalRoleNewOwner {
+			recipientSigned = true
+		}
+	}
+	return recipientSigned && len(pending.Approvals) >= transferPolicyOf(pending).Threshold
+}
+
+// SetTransferPolicy creates or replaces the approval policy for an asset type or a single asset
+func (s *SmartContract) SetTransferPolicy(ctx contractapi.TransactionContextInterface, scope string, scopeID string, threshold int, approverRoles []string, approverUsers []string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	adminID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if scope != PolicyScopeAssetType && scope != PolicyScopeAsset {
+		return fmt.Errorf("invalid policy scope %q. Must be %q or %q", scope, PolicyScopeAssetType, PolicyScopeAsset)
+	}
+	if strings.TrimSpace(scopeID) == "" {
+		return fmt.Errorf("policy scope ID is required")
+	}
+
+	policy := &TransferPolicy{
+		DocType:       "transfer_policy",
+		Scope:         scope,
+		ScopeID:       scopeID,
+		Threshold:     threshold,
+		ApproverRoles: dedupe(approverRoles),
+		ApproverUsers: dedupe(approverUsers),
+		UpdatedBy:     adminID,
+	}
+	// The owner of a single asset already signs in their own slot, so they cannot also be an approver.
+	// Owners under a type policy, and recipients, are only known per transfer (see policyForTransfer).
+	if scope == PolicyScopeAsset {
+		asset, err := s.ReadAsset(ctx, scopeID)
+		if err != nil {
+			return err
+		}
+		for _, user := range policy.ApproverUsers {
+			if user == asset.Owner {
+				return fmt.Errorf("%s owns asset %s and already approves its transfers; choose another approver", user, scopeID)
+			}
+		}
+	}
+	if policy.Threshold < defaultTransferThreshold || policy.Threshold > policy.slots() {
+		return fmt.Errorf("threshold must be between %d and %d (owner, recipient, %d approver user(s), %d approver role(s))",
+			defaultTransferThreshold, policy.slots(), len(policy.ApproverUsers), len(policy.ApproverRoles))
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+	policy.UpdatedAt = timestamp.Seconds
+
+	key, err := policyKey(ctx, scope, scopeID)
+	if err != nil {
+		return err
+	}
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(key, policyJSON); err != nil {
+		return fmt.Errorf("failed to store transfer policy: %v", err)
+	}
+
+	return ctx.GetStub().SetEvent("TransferPolicySet", policyJSON)
+}
+
+// DeleteTransferPolicy removes a stored policy; transfers already pending keep the policy they started with
+func (s *SmartContract) DeleteTransferPolicy(ctx contractapi.TransactionContextInterface, scope string, scopeID string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if _, err := activeCallerID(ctx); err != nil {
+		return err
+	}
+
+	policy, err := readTransferPolicy(ctx, scope, scopeID)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return fmt.Errorf("no transfer policy for %s %s", scope, scopeID)
+	}
+
+	key, err := policyKey(ctx, scope, scopeID)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return err
+	}
+
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent("TransferPolicyDeleted", policyJSON)
+}
+
+// GetTransferPolicy returns the policy a new transfer of the asset would be initiated under
+func (s *SmartContract) GetTransferPolicy(ctx contractapi.TransactionContextInterface, assetID string) (*TransferPolicy, error) {
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	return effectiveTransferPolicy(ctx, asset)
+}
+
+// GetAllTransferPolicies returns every stored transfer policy
+func (s *SmartContract) GetAllTransferPolicies(ctx contractapi.TransactionContextInterface) ([]*TransferPolicy, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(policyObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	policies := []*TransferPolicy{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var policy TransferPolicy
+		if err := json.Unmarshal(queryResponse.Value, &policy); err != nil {
+			continue
+		}
+		policies = append(policies, &policy)
+	}
+
+	return policies, nil
+}
+
+// dedupe trims values and drops blanks and duplicates, keeping the first occurrence order
+func dedupe(values []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
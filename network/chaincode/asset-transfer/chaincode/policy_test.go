@@ -0,0 +1,130 @@
+package chaincode
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTransferPolicySatisfied(t *testing.T) {
+	owner := Approval{Signer: "alice", Role: ApprovalRoleCurrentOwner}
+	recipient := Approval{Signer: "bob", Role: ApprovalRoleNewOwner}
+	escrow := Approval{Signer: "escrow", Role: ApprovalRoleApprover}
+	auditor := Approval{Signer: "carol", Role: approvalRolePrefix + RoleAuditor}
+	threeOfFour := &TransferPolicy{Threshold: 3, ApproverUsers: []string{"escrow"}, ApproverRoles: []string{RoleAuditor}}
+
+	tests := []struct {
+		name      string
+		policy    *TransferPolicy
+		approvals []Approval
+		want      bool
+	}{
+		{"default policy, owner only", nil, []Approval{owner}, false},
+		{"default policy, owner and recipient", nil, []Approval{owner, recipient}, true},
+		{"threshold reached without the recipient", &TransferPolicy{Threshold: 2, ApproverUsers: []string{"escrow"}}, []Approval{owner, escrow}, false},
+		{"3-of-4 below threshold", threeOfFour, []Approval{owner, recipient}, false},
+		{"3-of-4 with escrow", threeOfFour, []Approval{owner, recipient, escrow}, true},
+		{"3-of-4 with role holder", threeOfFour, []Approval{owner, recipient, auditor}, true},
+		{"3-of-4 every slot filled", threeOfFour, []Approval{owner, escrow, auditor, recipient}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending := &PendingTransfer{CurrentOwner: "alice", NewOwner: "bob", Policy: tt.policy, Approvals: tt.approvals}
+			if got := transferPolicySatisfied(pending); got != tt.want {
+				t.Fatalf("transferPolicySatisfied() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApprovalRoleForNamedApprovers(t *testing.T) {
+	// Policies without approver roles never consult the client identity, so no context is needed
+	policy := &TransferPolicy{Threshold: 3, ApproverUsers: []string{"escrow"}}
+	initiated := []Approval{{Signer: "alice", Role: ApprovalRoleCurrentOwner}}
+
+	tests := []struct {
+		name      string
+		caller    string
+		approvals []Approval
+		want      string
+		wantErr   bool
+	}{
+		{"recipient", "bob", initiated, ApprovalRoleNewOwner, false},
+		{"named approver", "escrow", initiated, ApprovalRoleApprover, false},
+		{"owner cannot sign twice", "alice", initiated, "", true},
+		{"recipient cannot sign twice", "bob", append(initiated, Approval{Signer: "bob", Role: ApprovalRoleNewOwner}), "", true},
+		{"outsider", "mallory", initiated, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending := &PendingTransfer{CurrentOwner: "alice", NewOwner: "bob", Policy: policy, Approvals: tt.approvals}
+			got, err := approvalRoleFor(nil, pending, tt.caller)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("approvalRoleFor(%q) error = %v, wantErr %v", tt.caller, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("approvalRoleFor(%q) = %q, want %q", tt.caller, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyForTransfer(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        *TransferPolicy
+		wantUsers     []string
+		wantThreshold int
+	}{
+		{"unrelated approvers kept", &TransferPolicy{Threshold: 3, ApproverUsers: []string{"escrow"}}, []string{"escrow"}, 3},
+		{"owner dropped and threshold capped", &TransferPolicy{Threshold: 3, ApproverUsers: []string{"alice"}}, []string{}, 2},
+		{"recipient dropped, other approver kept", &TransferPolicy{Threshold: 4, ApproverUsers: []string{"bob", "escrow"}}, []string{"escrow"}, 3},
+		{"role slots still count", &TransferPolicy{Threshold: 4, ApproverUsers: []string{"alice"}, ApproverRoles: []string{RoleAuditor}}, []string{}, 3},
+		{"lower threshold untouched", &TransferPolicy{Threshold: 2, ApproverUsers: []string{"alice", "escrow"}}, []string{"escrow"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policyForTransfer(tt.policy, "alice", "bob")
+			if !reflect.DeepEqual(got.ApproverUsers, tt.wantUsers) || got.Threshold != tt.wantThreshold {
+				t.Fatalf("policyForTransfer() = %v with threshold %d, want %v with threshold %d", got.ApproverUsers, got.Threshold, tt.wantUsers, tt.wantThreshold)
+			}
+		})
+	}
+}
+
+func TestTransferPolicySlots(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *TransferPolicy
+		want   int
+	}{
+		{"default", defaultTransferPolicy(), 2},
+		{"one user", &TransferPolicy{ApproverUsers: []string{"escrow"}}, 3},
+		{"users and roles", &TransferPolicy{ApproverUsers: []string{"escrow", "notary"}, ApproverRoles: []string{RoleAuditor}}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.slots(); got != tt.want {
+				t.Fatalf("slots() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{"nil", nil, []string{}},
+		{"blanks dropped", []string{"", "  "}, []string{}},
+		{"trimmed and deduplicated in order", []string{" escrow", "notary", "escrow ", "auditor"}, []string{"escrow", "notary", "auditor"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dedupe(tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("dedupe(%q) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
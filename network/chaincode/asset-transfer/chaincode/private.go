@@ -0,0 +1,238 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// assetPrivateCollection is the private data collection defined in collections_config.json.
+// Only its hash is written to the channel ledger; the details stay on member peers.
+const assetPrivateCollection = "assetPrivateDetailsCollection"
+
+// privateDetailsTransientKey is the transient map key carrying AssetPrivateDetails JSON.
+// Transient data is not recorded in the transaction, so the details never reach the public ledger.
+const privateDetailsTransientKey = "asset_private_details"
+
+// AssetPrivateDetails is the sensitive part of an asset, kept in the private data collection
+type AssetPrivateDetails struct {
+	DocType       string   `json:"docType"` // "asset_private"
+	AssetID       string   `json:"assetId"`
+	PurchasePrice float64  `json:"purchasePrice,omitempty"`
+	SerialNumber  string   `json:"serialNumber,omitempty"`
+	Documents     []string `json:"documents,omitempty"` // Document URLs or IPFS CIDs
+	Notes         string   `json:"notes,omitempty"`
+	UpdatedAt     int64    `json:"updatedAt"`
+	UpdatedBy     string   `json:"updatedBy"`
+}
+
+// PrivateDetailsEvent is the public payload of AssetPrivateDetailsSet: who changed what, never the details
+type PrivateDetailsEvent struct {
+	AssetID   string `json:"assetId"`
+	Hash      string `json:"hash"` // Hex SHA-256 of the stored details
+	UpdatedAt int64  `json:"updatedAt"`
+	UpdatedBy string `json:"updatedBy"`
+}
+
+// privateDetailsFromTransient reads AssetPrivateDetails from the transient map.
+// It returns nil when the transaction carries no private details.
+func privateDetailsFromTransient(ctx contractapi.TransactionContextInterface) (*AssetPrivateDetails, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transient data: %v", err)
+	}
+	detailsJSON, ok := transientMap[privateDetailsTransientKey]
+	if !ok || len(detailsJSON) == 0 {
+		return nil, nil
+	}
+
+	var details AssetPrivateDetails
+	if err := json.Unmarshal(detailsJSON, &details); err != nil {
+		return nil, fmt.Errorf("invalid %s transient data: %v", privateDetailsTransientKey, err)
+	}
+	if details.PurchasePrice < 0 {
+		return nil, fmt.Errorf("purchase price cannot be negative")
+	}
+	return &details, nil
+}
+
+// putAssetPrivateDetails stamps and stores the details for an asset.
+// It returns the public AssetPrivateDetailsSet payload; emitting it is left to the caller
+// because CreateAsset already emits AssetCreated in the same transaction.
+func putAssetPrivateDetails(ctx contractapi.TransactionContextInterface, assetID string, details *AssetPrivateDetails, actorID string, now int64) (*PrivateDetailsEvent, error) {
+	details.DocType = "asset_private"
+	details.AssetID = assetID
+	details.UpdatedAt = now
+	details.UpdatedBy = actorID
+
+	key, err := assetKey(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutPrivateData(assetPrivateCollection, key, detailsJSON); err != nil {
+		return nil, fmt.Errorf("failed to store private details: %v", err)
+	}
+
+	hash := sha256.Sum256(detailsJSON)
+	return &PrivateDetailsEvent{AssetID: assetID, Hash: hex.EncodeToString(hash[:]), UpdatedAt: now, UpdatedBy: actorID}, nil
+}
+
+// canReadPrivateDetails reports whether the user is the owner, a share holder or, through one of
+// their principals (user, role or group), a viewer with an unexpired full grant.
+// The public "EVERYONE" grant does not extend to private details.
+func canReadPrivateDetails(asset *Asset, userID string, principals []string, now int64) bool {
+	if asset.Owner == userID || holdsShare(asset, userID) {
+		return true
+	}
+	return hasViewerAccess(asset, principals, PermissionFull, now)
+}
+
+// SetAssetPrivateDetails creates or replaces the private details of an asset.
+// The details are passed in the transient map under "asset_private_details"; only the owner may set them.
+func (s *SmartContract) SetAssetPrivateDetails(ctx contractapi.TransactionContextInterface, assetID string) error {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if asset.Owner != callerID {
+		return fmt.Errorf("only the owner can set private details. Owner: %s, Caller: %s", asset.Owner, callerID)
+	}
+	if err := requireNoTransferLock(asset); err != nil {
+		return err
+	}
+	if err := requireNotArchived(asset); err != nil {
+		return err
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return err
+	}
+
+	details, err := privateDetailsFromTransient(ctx)
+	if err != nil {
+		return err
+	}
+	if details == nil {
+		return fmt.Errorf("private details must be passed in the transient map under %q", privateDetailsTransientKey)
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+	event, err := putAssetPrivateDetails(ctx, assetID, details, callerID, timestamp.Seconds)
+	if err != nil {
+		return err
+	}
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent("AssetPrivateDetailsSet", eventJSON)
+}
+
+// ReadAssetPrivateDetails returns the private details of an asset to its owner or a granted viewer
+func (s *SmartContract) ReadAssetPrivateDetails(ctx contractapi.TransactionContextInterface, assetID string) (*AssetPrivateDetails, error) {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	principals, err := s.viewerPrincipals(ctx, callerID)
+	if err != nil {
+		return nil, err
+	}
+	if !canReadPrivateDetails(asset, callerID, principals, timestamp.Seconds) {
+		return nil, fmt.Errorf("%s is not allowed to read the private details of asset %s", callerID, assetID)
+	}
+
+	key, err := assetKey(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	detailsJSON, err := ctx.GetStub().GetPrivateData(assetPrivateCollection, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private details: %v", err)
+	}
+	if detailsJSON == nil {
+		return nil, fmt.Errorf("asset %s has no private details", assetID)
+	}
+
+	var details AssetPrivateDetails
+	if err := json.Unmarshal(detailsJSON, &details); err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// GetAssetPrivateDetailsHash returns the hex SHA-256 of an asset's private details as recorded on the channel.
+// Anyone can read it, including peers outside the collection.
+func (s *SmartContract) GetAssetPrivateDetailsHash(ctx contractapi.TransactionContextInterface, assetID string) (string, error) {
+	key, err := assetKey(ctx, assetID)
+	if err != nil {
+		return "", err
+	}
+	hash, err := ctx.GetStub().GetPrivateDataHash(assetPrivateCollection, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read private details hash: %v", err)
+	}
+	if hash == nil {
+		return "", fmt.Errorf("asset %s has no private details", assetID)
+	}
+	return hex.EncodeToString(hash), nil
+}
+
+// VerifyAssetPrivateDetails checks details handed over off-chain (e.g. by the seller to a buyer)
+// against the on-chain hash. The details are passed in the transient map like SetAssetPrivateDetails;
+// they must include the updatedAt/updatedBy stamp returned by ReadAssetPrivateDetails.
+func (s *SmartContract) VerifyAssetPrivateDetails(ctx contractapi.TransactionContextInterface, assetID string) (bool, error) {
+	onChainHash, err := s.GetAssetPrivateDetailsHash(ctx, assetID)
+	if err != nil {
+		return false, err
+	}
+
+	details, err := privateDetailsFromTransient(ctx)
+	if err != nil {
+		return false, err
+	}
+	if details == nil {
+		return false, fmt.Errorf("private details must be passed in the transient map under %q", privateDetailsTransientKey)
+	}
+
+	// Re-marshal so the bytes match what putAssetPrivateDetails stored, whatever the client's key order
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return false, err
+	}
+	hash := sha256.Sum256(detailsJSON)
+	return hex.EncodeToString(hash[:]) == onChainHash, nil
+}
+
+// deleteAssetPrivateDetails removes the private details of a deleted asset
+func deleteAssetPrivateDetails(ctx contractapi.TransactionContextInterface, assetID string) error {
+	key, err := assetKey(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().DelPrivateData(assetPrivateCollection, key); err != nil {
+		return fmt.Errorf("failed to delete private details: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Design documents and index names shipped in META-INF/statedb/couchdb/indexes.
+// Each dedicated query hints its index so CouchDB never falls back to a full scan.
+var (
+	ownerIndex  = []string{"_design/indexOwnerDoc", "indexOwner"}
+	typeIndex   = []string{"_design/indexTypeDoc", "indexType"}
+	statusIndex = []string{"_design/indexStatusDoc", "indexStatus"}
+	viewerIndex = []string{"_design/indexViewersDoc", "indexViewers"}
+	holderIndex = []string{"_design/indexHoldersDoc", "indexHolders"}
+)
+
+// richQuery is the subset of a CouchDB Mango query the chaincode builds or accepts
+type richQuery struct {
+	Selector map[string]interface{} `json:"selector"`
+	UseIndex []string               `json:"use_index,omitempty"`
+}
+
+// QueryAssets runs a CouchDB rich query over assets and returns one page of results.
+// queryJSON is either a bare selector ({"owner":"Tomoko"}) or a Mango query with "selector"
+// and optionally "use_index". The selector is always restricted to docType "asset".
+// Rich queries need CouchDB as the state database and can only be evaluated, not submitted.
+func (s *SmartContract) QueryAssets(ctx contractapi.TransactionContextInterface, queryJSON string, pageSize int32, bookmark string) (*AssetPage, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(queryJSON), &raw); err != nil {
+		return nil, fmt.Errorf("invalid query JSON: %v", err)
+	}
+
+	query := richQuery{Selector: raw}
+	if selector, ok := raw["selector"].(map[string]interface{}); ok {
+		query.Selector = selector
+		if useIndex, ok := raw["use_index"].([]interface{}); ok {
+			for _, part := range useIndex {
+				if name, ok := part.(string); ok {
+					query.UseIndex = append(query.UseIndex, name)
+				}
+			}
+		}
+	}
+
+	return s.queryAssetPage(ctx, query, pageSize, bookmark)
+}
+
+// QueryAssetsByOwner returns one page of the assets owned by a user
+func (s *SmartContract) QueryAssetsByOwner(ctx contractapi.TransactionContextInterface, owner string, pageSize int32, bookmark string) (*AssetPage, error) {
+	query := richQuery{Selector: map[string]interface{}{"owner": owner}, UseIndex: ownerIndex}
+	return s.queryAssetPage(ctx, query, pageSize, bookmark)
+}
+
+// QueryAssetsByType returns one page of the assets of a type
+func (s *SmartContract) QueryAssetsByType(ctx contractapi.TransactionContextInterface, assetType string, pageSize int32, bookmark string) (*AssetPage, error) {
+	query := richQuery{Selector: map[string]interface{}{"type": assetType}, UseIndex: typeIndex}
+	return s.queryAssetPage(ctx, query, pageSize, bookmark)
+}
+
+// QueryAssetsByStatus returns one page of the assets in a status
+func (s *SmartContract) QueryAssetsByStatus(ctx contractapi.TransactionContextInterface, status string, pageSize int32, bookmark string) (*AssetPage, error) {
+	query := richQuery{Selector: map[string]interface{}{"status": status}, UseIndex: statusIndex}
+	return s.queryAssetPage(ctx, query, pageSize, bookmark)
+}
+
+// QueryAssetsByViewer returns one page of the assets a user has been granted view access to,
+// directly or through their role or groups. Public (EVERYONE) assets are not included.
+// Assets whose grant has expired but not yet been swept are left out, so a page may come back short.
+func (s *SmartContract) QueryAssetsByViewer(ctx contractapi.TransactionContextInterface, viewerID string, pageSize int32, bookmark string) (*AssetPage, error) {
+	principals, err := s.viewerPrincipals(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	// viewerPrincipals always ends with EVERYONE
+	principals = principals[:len(principals)-1]
+
+	query := richQuery{
+		Selector: map[string]interface{}{"viewers": map[string]interface{}{"$elemMatch": map[string]interface{}{"$in": principals}}},
+		UseIndex: viewerIndex,
+	}
+	page, err := s.queryAssetPage(ctx, query, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	active := []*Asset{}
+	for _, asset := range page.Records {
+		if hasViewerAccess(asset, principals, PermissionMetadata, timestamp.Seconds) {
+			active = append(active, asset)
+		}
+	}
+	page.Records = active
+	return page, nil
+}
+
+// QueryAssetsByHolder returns one page of the assets a user owns outright or holds any share of
+func (s *SmartContract) QueryAssetsByHolder(ctx contractapi.TransactionContextInterface, holderID string, pageSize int32, bookmark string) (*AssetPage, error) {
+	query := richQuery{
+		Selector: map[string]interface{}{"holders": map[string]interface{}{"$elemMatch": map[string]interface{}{"$eq": holderID}}},
+		UseIndex: holderIndex,
+	}
+	return s.queryAssetPage(ctx, query, pageSize, bookmark)
+}
+
+// queryAssetPage pins the selector to assets, runs the paginated rich query and collects the page
+func (s *SmartContract) queryAssetPage(ctx contractapi.TransactionContextInterface, query richQuery, pageSize int32, bookmark string) (*AssetPage, error) {
+	if err := validatePageSize(pageSize); err != nil {
+		return nil, err
+	}
+
+	// Users, transfers and policies share the state database, so never let a query escape the asset documents
+	query.Selector["docType"] = "asset"
+	queryString, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(string(queryString), pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("rich query failed (CouchDB state database required): %v", err)
+	}
+	defer resultsIterator.Close()
+
+	page := &AssetPage{Records: []*Asset{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var asset Asset
+		if err := json.Unmarshal(queryResponse.Value, &asset); err != nil {
+			return nil, err
+		}
+		page.Records = append(page.Records, &asset)
+	}
+	page.FetchedRecordsCount = metadata.FetchedRecordsCount
+	page.Bookmark = nextBookmark(metadata.FetchedRecordsCount, pageSize, metadata.Bookmark)
+
+	return page, nil
+}
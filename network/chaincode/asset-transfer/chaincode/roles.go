@@ -0,0 +1,41 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// roleAttribute is the Fabric CA certificate attribute carrying the user's role
+const roleAttribute = "role"
+
+// Roles recognised by the ledger. They match User.Role and the CA "role" attribute.
+const (
+	RoleAdmin   = "Admin"
+	RoleUser    = "User"
+	RoleAuditor = "Auditor"
+	RoleSystem  = "System" // Backend service identity for scheduled housekeeping; has no User record
+)
+
+// requireRole fails unless the caller's enrollment certificate carries one of the given roles.
+// The certificate attribute is issued by the CA, so it cannot be forged by the client.
+func requireRole(ctx contractapi.TransactionContextInterface, roles ...string) error {
+	for _, role := range roles {
+		if err := ctx.GetClientIdentity().AssertAttributeValue(roleAttribute, role); err == nil {
+			return nil
+		}
+	}
+
+	callerID, _ := getCallerID(ctx)
+	return fmt.Errorf("access denied: %s requires role %v", callerID, roles)
+}
+
+// requireAdmin is a shorthand for admin-only functions
+func requireAdmin(ctx contractapi.TransactionContextInterface) error {
+	return requireRole(ctx, RoleAdmin)
+}
+
+// requireAuditor is a shorthand for auditor functions; admins may always act as auditors
+func requireAuditor(ctx contractapi.TransactionContextInterface) error {
+	return requireRole(ctx, RoleAuditor, RoleAdmin)
+}
@@ -0,0 +1,232 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Fractional ownership. An asset with TotalUnits > 0 is held in shares: Shares maps each
+// holder to their units and always adds up to TotalUnits. Owner is kept as the largest
+// holder (ties go to the alphabetically first ID), who manages the asset (metadata, viewers).
+// Whole-asset transfers are only possible for a holder of every unit; partial holdings
+// move with TransferShares.
+
+// Holding is one holder's share of an asset as reported by GetAssetHoldings
+type Holding struct {
+	Holder     string  `json:"holder"`
+	Units      int64   `json:"units"`
+	TotalUnits int64   `json:"totalUnits"`
+	Percent    float64 `json:"percent"`
+}
+
+// SharesTransferredEvent is the payload of SharesTransferred: the asset after the move plus the move itself
+type SharesTransferredEvent struct {
+	Asset *Asset `json:"asset"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Units int64  `json:"units"`
+}
+
+// isFractional reports whether the asset is held in shares
+func isFractional(asset *Asset) bool {
+	return asset.TotalUnits > 0
+}
+
+// holdersOf returns the sorted IDs holding any part of the asset; a whole asset is held by its owner
+func holdersOf(asset *Asset) []string {
+	if !isFractional(asset) {
+		return []string{asset.Owner}
+	}
+	holders := []string{}
+	for holder, units := range asset.Shares {
+		if units > 0 {
+			holders = append(holders, holder)
+		}
+	}
+	sort.Strings(holders)
+	return holders
+}
+
+// holdsShare reports whether the user holds any part of the asset
+func holdsShare(asset *Asset, userID string) bool {
+	if !isFractional(asset) {
+		return asset.Owner == userID
+	}
+	return asset.Shares[userID] > 0
+}
+
+// settleShares drops emptied holdings and makes the largest holder the owner
+func settleShares(asset *Asset) {
+	for holder, units := range asset.Shares {
+		if units <= 0 {
+			delete(asset.Shares, holder)
+		}
+	}
+	// holdersOf is sorted, so a strict comparison keeps the alphabetically first holder on ties
+	owner, largest := "", int64(0)
+	for _, holder := range holdersOf(asset) {
+		if asset.Shares[holder] > largest {
+			owner, largest = holder, asset.Shares[holder]
+		}
+	}
+	asset.Owner = owner
+}
+
+// assignOwner hands the whole asset to a new owner, moving every unit if it is held in shares
+func assignOwner(asset *Asset, newOwner string) {
+	asset.Owner = newOwner
+	if isFractional(asset) {
+		asset.Shares = map[string]int64{newOwner: asset.TotalUnits}
+	}
+}
+
+// requireSoleHolder fails when a fractional asset has other holders than the user,
+// i.e. when moving the whole asset would take away someone else's units
+func requireSoleHolder(asset *Asset, userID string) error {
+	if isFractional(asset) && asset.Shares[userID] != asset.TotalUnits {
+		return fmt.Errorf("asset %s is held in shares by %v; use TransferShares to move part of a holding", asset.ID, holdersOf(asset))
+	}
+	return nil
+}
+
+// FractionalizeAsset splits a whole asset into totalUnits shares, all held by the current owner
+func (s *SmartContract) FractionalizeAsset(ctx contractapi.TransactionContextInterface, assetID string, totalUnits int64) error {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if asset.Owner != callerID {
+		return fmt.Errorf("only the owner can fractionalize an asset. Owner: %s, Caller: %s", asset.Owner, callerID)
+	}
+	if isFractional(asset) {
+		return fmt.Errorf("asset %s is already held in %d shares", assetID, asset.TotalUnits)
+	}
+	if err := requireNoTransferLock(asset); err != nil {
+		return err
+	}
+	if err := requireNotArchived(asset); err != nil {
+		return err
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return err
+	}
+	if err := requireNoBundle(asset); err != nil {
+		return err
+	}
+	if err := requireNotOnLoan(asset); err != nil {
+		return err
+	}
+	if totalUnits < 2 {
+		return fmt.Errorf("total units must be at least 2, got %d", totalUnits)
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+
+	asset.TotalUnits = totalUnits
+	asset.Shares = map[string]int64{callerID: totalUnits}
+	asset.UpdatedAt = timestamp.Seconds
+	asset.LastModifiedBy = callerID
+	asset.Sequence = asset.Sequence + 1
+
+	assetJSON, err := putAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent("AssetFractionalized", assetJSON)
+}
+
+// TransferShares moves units of the caller's holding to another user.
+// Share transfers are single-signature: the holder gives away units they own outright.
+func (s *SmartContract) TransferShares(ctx contractapi.TransactionContextInterface, assetID string, recipient string, units int64) error {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if !isFractional(asset) {
+		return fmt.Errorf("asset %s is not held in shares; use InitiateTransfer", assetID)
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return err
+	}
+	if err := requireNoTransferLock(asset); err != nil {
+		return err
+	}
+	if err := requireTransferable(asset); err != nil {
+		return err
+	}
+
+	if recipient == "" || recipient == callerID {
+		return fmt.Errorf("a recipient other than yourself is required")
+	}
+	if err := requireUnlocked(ctx, recipient); err != nil {
+		return err
+	}
+	held := asset.Shares[callerID]
+	if units <= 0 || units > held {
+		return fmt.Errorf("units must be between 1 and your holding of %d, got %d", held, units)
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+	if err := requireAttestations(ctx, asset, timestamp.Seconds); err != nil {
+		return err
+	}
+
+	asset.Shares[callerID] = held - units
+	asset.Shares[recipient] = asset.Shares[recipient] + units
+	settleShares(asset)
+	asset.UpdatedAt = timestamp.Seconds
+	asset.LastModifiedBy = callerID
+	asset.Sequence = asset.Sequence + 1
+
+	if _, err := putAsset(ctx, asset); err != nil {
+		return err
+	}
+
+	eventJSON, err := json.Marshal(SharesTransferredEvent{Asset: asset, From: callerID, To: recipient, Units: units})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent("SharesTransferred", eventJSON)
+}
+
+// GetAssetHoldings reports each holder's share of an asset, largest first.
+// A whole asset is reported as a single holding of 1 unit by its owner.
+func (s *SmartContract) GetAssetHoldings(ctx contractapi.TransactionContextInterface, assetID string) ([]*Holding, error) {
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if !isFractional(asset) {
+		return []*Holding{{Holder: asset.Owner, Units: 1, TotalUnits: 1, Percent: 100}}, nil
+	}
+
+	holdings := []*Holding{}
+	for _, holder := range holdersOf(asset) {
+		units := asset.Shares[holder]
+		holdings = append(holdings, &Holding{
+			Holder:     holder,
+			Units:      units,
+			TotalUnits: asset.TotalUnits,
+			Percent:    float64(units) * 100 / float64(asset.TotalUnits),
+		})
+	}
+	sort.SliceStable(holdings, func(i, j int) bool { return holdings[i].Units > holdings[j].Units })
+	return holdings, nil
+}
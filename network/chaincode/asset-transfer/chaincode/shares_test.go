@@ -0,0 +1,108 @@
+package chaincode
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSettleShares(t *testing.T) {
+	tests := []struct {
+		name       string
+		shares     map[string]int64
+		wantOwner  string
+		wantShares map[string]int64
+	}{
+		{"largest holder owns", map[string]int64{"alice": 30, "bob": 70}, "bob", map[string]int64{"alice": 30, "bob": 70}},
+		{"tie goes to the alphabetically first", map[string]int64{"carol": 50, "bob": 50}, "bob", map[string]int64{"carol": 50, "bob": 50}},
+		{"emptied holdings are dropped", map[string]int64{"alice": 0, "bob": 100}, "bob", map[string]int64{"bob": 100}},
+		{"negative holdings are dropped", map[string]int64{"alice": -5, "bob": 40, "carol": 60}, "carol", map[string]int64{"bob": 40, "carol": 60}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asset := &Asset{Owner: "alice", TotalUnits: 100, Shares: tt.shares}
+			settleShares(asset)
+			if asset.Owner != tt.wantOwner {
+				t.Fatalf("owner = %q, want %q", asset.Owner, tt.wantOwner)
+			}
+			if !reflect.DeepEqual(asset.Shares, tt.wantShares) {
+				t.Fatalf("shares = %v, want %v", asset.Shares, tt.wantShares)
+			}
+		})
+	}
+}
+
+func TestHoldersOf(t *testing.T) {
+	tests := []struct {
+		name  string
+		asset *Asset
+		want  []string
+	}{
+		{"whole asset is held by its owner", &Asset{Owner: "alice"}, []string{"alice"}},
+		{"sorted holders", &Asset{Owner: "carol", TotalUnits: 10, Shares: map[string]int64{"carol": 6, "alice": 4}}, []string{"alice", "carol"}},
+		{"empty holdings skipped", &Asset{Owner: "bob", TotalUnits: 10, Shares: map[string]int64{"alice": 0, "bob": 10}}, []string{"bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := holdersOf(tt.asset); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("holdersOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHoldsShare(t *testing.T) {
+	fractional := &Asset{Owner: "alice", TotalUnits: 10, Shares: map[string]int64{"alice": 7, "bob": 3}}
+	tests := []struct {
+		name  string
+		asset *Asset
+		user  string
+		want  bool
+	}{
+		{"owner of whole asset", &Asset{Owner: "alice"}, "alice", true},
+		{"stranger to whole asset", &Asset{Owner: "alice"}, "bob", false},
+		{"minority holder", fractional, "bob", true},
+		{"non-holder", fractional, "carol", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := holdsShare(tt.asset, tt.user); got != tt.want {
+				t.Fatalf("holdsShare(%q) = %v, want %v", tt.user, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireSoleHolder(t *testing.T) {
+	tests := []struct {
+		name    string
+		asset   *Asset
+		user    string
+		wantErr bool
+	}{
+		{"whole asset", &Asset{Owner: "alice"}, "alice", false},
+		{"holder of every unit", &Asset{Owner: "alice", TotalUnits: 10, Shares: map[string]int64{"alice": 10}}, "alice", false},
+		{"majority holder", &Asset{Owner: "alice", TotalUnits: 10, Shares: map[string]int64{"alice": 9, "bob": 1}}, "alice", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := requireSoleHolder(tt.asset, tt.user)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("requireSoleHolder(%q) error = %v, wantErr %v", tt.user, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAssignOwner(t *testing.T) {
+	whole := &Asset{Owner: "alice"}
+	assignOwner(whole, "bob")
+	if whole.Owner != "bob" || whole.Shares != nil {
+		t.Fatalf("whole asset: owner = %q, shares = %v", whole.Owner, whole.Shares)
+	}
+
+	fractional := &Asset{Owner: "alice", TotalUnits: 10, Shares: map[string]int64{"alice": 10}}
+	assignOwner(fractional, "bob")
+	if want := map[string]int64{"bob": 10}; fractional.Owner != "bob" || !reflect.DeepEqual(fractional.Shares, want) {
+		t.Fatalf("fractional asset: owner = %q, shares = %v, want bob with %v", fractional.Owner, fractional.Shares, want)
+	}
+}
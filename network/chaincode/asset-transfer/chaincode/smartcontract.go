@@ -25,13 +25,31 @@ type Asset struct {
 	Name           string   `json:"name"`          // Product Name (e.g., "MacBook Pro")
 	Type           string   `json:"type"`          // Category (e.g., "Electronics", "RealEstate")
 	Owner          string   `json:"owner"`         // Current Owner
-	Status         string   `json:"status"`        // Status (e.g., "Available", "Sold", "Locked")
+	Status         string   `json:"status"`        // Status, one of the AssetStatus* values (see status.go)
 	MetadataURL    string   `json:"metadata_url"`  // External Metadata (e.g. IPFS hash)
 	MetadataHash   string   `json:"metadata_hash"` // Integrity Check (SHA-256)
+	Attributes     map[string]interface{} `json:"attributes,omitempty"` // Custom attributes declared by the asset type (see assettypes.go)
 	Viewers        []string `json:"viewers"`       // List of distinct UserIDs allowed to view. "EVERYONE" for public.
+	Grants         []*ViewerGrant `json:"grants,omitempty"` // Permission and expiry per viewer (see access.go)
 	UpdatedAt      int64    `json:"updatedAt"`     // Timestamp of last update
 	LastModifiedBy string   `json:"lastModifiedBy"` // Provenance: Who made the last change
 	Sequence       uint64   `json:"sequence"`      // Eventual Consistency Check
+	StatusBeforeTransfer string `json:"statusBeforeTransfer,omitempty"` // Status to restore when a pending transfer ends without executing
+	TotalUnits     int64            `json:"totalUnits,omitempty"` // Fractional assets: fixed number of shares (see shares.go)
+	Shares         map[string]int64 `json:"shares,omitempty"`     // Fractional assets: holder -> units, sums to TotalUnits
+	Holders        []string         `json:"holders"`              // Everyone holding any part of the asset (maintained by putAsset, indexed for queries)
+	ParentID       string           `json:"parentId,omitempty"`   // Bundle parent this asset is attached to (see bundle.go)
+	Children       []string         `json:"children,omitempty"`   // Assets attached to this one; they move with it
+	Custodian      string           `json:"custodian,omitempty"`    // Borrower holding the asset on loan; ownership is unchanged (see custody.go)
+	CustodySince   int64            `json:"custodySince,omitempty"` // Start of the current loan
+	CustodyUntil   int64            `json:"custodyUntil,omitempty"` // Due date of the current loan
+	StatusBeforeArchive string      `json:"statusBeforeArchive,omitempty"` // Status to restore when an archived asset is restored (see archive.go)
+	ArchivedAt     int64            `json:"archivedAt,omitempty"`
+	ArchivedBy     string           `json:"archivedBy,omitempty"`
+	ArchiveReason  string           `json:"archiveReason,omitempty"`
+	Attestations   []*Attestation   `json:"attestations,omitempty"` // Auditor attestations, current and expired (see attestations.go)
+	Freeze         *FreezeRecord    `json:"freeze,omitempty"`        // Active legal freeze, nil when not frozen (see freeze.go)
+	FreezeHistory  []*FreezeRecord  `json:"freezeHistory,omitempty"` // Every freeze and unfreeze, oldest first
 }
 
 // User describes the participant in the network
@@ -47,10 +65,23 @@ type User struct {
 // ... existing code ...
 
 // SetUserStatus updates the status of a user (e.g. "Locked" or "Active")
-func (s *SmartContract) SetUserStatus(ctx contractapi.TransactionContextInterface, targetUserID string, newStatus string, adminID string) error {
-	// 1. Verify Admin (Caller)
-	// Ideally we check Client Identity here, but passing adminID for simulation/logging consistency
-	
+func (s *SmartContract) SetUserStatus(ctx contractapi.TransactionContextInterface, targetUserID string, newStatus string) error {
+	// 1. Verify Admin (Caller) from the client certificate
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	adminID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	if adminID == targetUserID {
+		return fmt.Errorf("admins cannot change their own status")
+	}
+
+	if newStatus != UserStatusActive && newStatus != UserStatusLocked {
+		return fmt.Errorf("invalid status %s. Must be %s or %s", newStatus, UserStatusActive, UserStatusLocked)
+	}
+
 	// 2. Get Target User
 	user, err := s.ReadUser(ctx, targetUserID)
 	if err != nil {
@@ -66,13 +97,8 @@ func (s *SmartContract) SetUserStatus(ctx contractapi.TransactionContextInterfac
 	user.Status = newStatus
 	user.UpdatedAt = timestamp.Seconds
 	user.Sequence = user.Sequence + 1
-	
-	userBytes, err := json.Marshal(user)
-	if err != nil {
-		return err
-	}
 
-	err = ctx.GetStub().PutState(targetUserID, userBytes)
+	userBytes, err := putUser(ctx, user)
 	if err != nil {
 		return err
 	}
@@ -95,18 +121,26 @@ type PendingTransfer struct {
 	AssetName       string     `json:"asset_name"`
 	CurrentOwner    string     `json:"current_owner"`
 	NewOwner        string     `json:"new_owner"`
-	Status          string     `json:"status"` // PENDING, EXECUTED, REJECTED, EXPIRED
+	Status          string     `json:"status"` // PENDING, FROZEN, EXECUTED, REJECTED, EXPIRED, INVALID, CANCELLED
 	Approvals       []Approval `json:"approvals"`
 	CreatedAt       int64      `json:"created_at"`       // Unix timestamp
-	ExpiresAt       int64      `json:"expires_at"`       // Unix timestamp (24h from creation)
+	ExpiresAt       int64      `json:"expires_at"`       // Unix timestamp (CreatedAt + approval window, see expiry.go)
 	ExecutedAt      int64      `json:"executed_at"`
 	RejectionReason string     `json:"rejection_reason"`
+	CancelledBy        string `json:"cancelled_by,omitempty"`        // Initiator or admin who cancelled
+	CancellationReason string `json:"cancellation_reason,omitempty"`
+	CancelledByAdmin   bool   `json:"cancelled_by_admin,omitempty"`  // Admin override rather than initiator withdrawal
+	Policy             *TransferPolicy `json:"policy,omitempty"`     // Approval policy snapshot taken at initiation (see policy.go)
+	RequiredApprovals  int    `json:"required_approvals"`            // Policy threshold, owner and recipient included
+	BundledAssets      []string `json:"bundled_assets,omitempty"`    // Attached assets locked with the parent and moved on execution
+	Price              int64    `json:"price,omitempty"`             // Tokens the new owner pays on execution (0 = no payment, see tokens.go)
+	FrozenAt           int64    `json:"frozen_at,omitempty"`         // Set while the asset's freeze holds the transfer (see freeze.go)
 }
 
 // Approval represents a single signature on a pending transfer
 type Approval struct {
 	Signer    string `json:"signer"`
-	Role      string `json:"role"`      // CURRENT_OWNER or NEW_OWNER
+	Role      string `json:"role"`      // CURRENT_OWNER, NEW_OWNER, APPROVER or ROLE:<role>
 	Timestamp int64  `json:"timestamp"` // Unix timestamp
 	Comment   string `json:"comment,omitempty"`
 }
@@ -127,16 +161,10 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 		{DocType: "asset", ID: "asset6", Name: "Bitcoin", Type: "Crypto", Owner: "Michel", Status: "Available", MetadataURL: "http://example.com/asset6.json", MetadataHash: "hash_asset6", Viewers: []string{"EVERYONE"}, UpdatedAt: ts, LastModifiedBy: "System", Sequence: 1},
 	}
 
-	for _, asset := range assets {
-		assetJSON, err := json.Marshal(asset)
-		if err != nil {
+	for i := range assets {
+		if _, err := putAsset(ctx, &assets[i]); err != nil {
 			return err
 		}
-
-		err = ctx.GetStub().PutState(asset.ID, assetJSON)
-		if err != nil {
-			return fmt.Errorf("failed to put to world state. %v", err)
-		}
 	}
 
 	// Seed Default Users (PII removed)
@@ -152,22 +180,18 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 		{DocType: "user", ID: "auditor", Role: "Auditor", Status: "Active", UpdatedAt: ts, Sequence: 1},
 	}
 
-	for _, user := range users {
-		userJSON, err := json.Marshal(user)
-		if err != nil {
+	for i := range users {
+		if _, err := putUser(ctx, &users[i]); err != nil {
 			return err
 		}
-		err = ctx.GetStub().PutState(user.ID, userJSON)
-		if err != nil {
-			return fmt.Errorf("failed to put user to world state. %v", err)
-		}
 	}
 
 	return nil
 }
 
 // CreateAsset issues a new asset to the world state with given details.
-func (s *SmartContract) CreateAsset(ctx contractapi.TransactionContextInterface, id string, name string, assetType string, owner string, status string, metadataUrl string, metadataHash string) error {
+// attributesJSON is a JSON object checked against the registered asset type; empty means no attributes.
+func (s *SmartContract) CreateAsset(ctx contractapi.TransactionContextInterface, id string, name string, assetType string, owner string, status string, metadataUrl string, metadataHash string, attributesJSON string) error {
 	exists, err := s.AssetExists(ctx, id)
 	if err != nil {
 		return err
@@ -175,14 +199,29 @@ func (s *SmartContract) CreateAsset(ctx contractapi.TransactionContextInterface,
 	if exists {
 		return fmt.Errorf("the asset %s already exists", id)
 	}
+	if err := validateAssetStatus(status); err != nil {
+		return err
+	}
+	attributes, err := parseAttributes(attributesJSON)
+	if err != nil {
+		return err
+	}
+	if err := validateAttributes(ctx, assetType, attributes); err != nil {
+		return err
+	}
 
 	timestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
 		return err
 	}
-	// In a real scenario, we might use the submitter's ID
-	rawID, _ := ctx.GetClientIdentity().GetID()
-	submitterID := extractUsername(rawID)
+	// The owner is taken on trust only if it matches the submitting certificate
+	submitterID, err := assertCaller(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if err := requireUnlocked(ctx, submitterID); err != nil {
+		return err
+	}
 
 	asset := Asset{
 		DocType:        "asset",
@@ -193,20 +232,28 @@ func (s *SmartContract) CreateAsset(ctx contractapi.TransactionContextInterface,
 		Status:         status,
 		MetadataURL:    metadataUrl,
 		MetadataHash:   metadataHash,
+		Attributes:     attributes,
 		Viewers:        []string{}, // Default: Private to Owner
 		UpdatedAt:      timestamp.Seconds,
 		LastModifiedBy: submitterID,
 		Sequence:       1,
 	}
-	assetJSON, err := json.Marshal(asset)
+	assetJSON, err := putAsset(ctx, &asset)
 	if err != nil {
 		return err
 	}
 
-	err = ctx.GetStub().PutState(id, assetJSON)
+	// Optional private details (price, serial number, documents) arrive as transient data
+	privateDetails, err := privateDetailsFromTransient(ctx)
 	if err != nil {
 		return err
 	}
+	if privateDetails != nil {
+		if _, err := putAssetPrivateDetails(ctx, id, privateDetails, submitterID, timestamp.Seconds); err != nil {
+			return err
+		}
+	}
+
 	// Emit Event for Sync
 	return ctx.GetStub().SetEvent("AssetCreated", assetJSON)
 }
@@ -214,7 +261,11 @@ func (s *SmartContract) CreateAsset(ctx contractapi.TransactionContextInterface,
 
 // ReadAsset returns the asset stored in the world state with given id.
 func (s *SmartContract) ReadAsset(ctx contractapi.TransactionContextInterface, id string) (*Asset, error) {
-	assetJSON, err := ctx.GetStub().GetState(id)
+	key, err := assetKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	assetJSON, err := ctx.GetStub().GetState(key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from world state: %v", err)
 	}
@@ -232,7 +283,8 @@ func (s *SmartContract) ReadAsset(ctx contractapi.TransactionContextInterface, i
 }
 
 // UpdateAsset updates an existing asset in the world state with provided parameters.
-func (s *SmartContract) UpdateAsset(ctx contractapi.TransactionContextInterface, id string, name string, assetType string, owner string, status string, metadataUrl string, metadataHash string) error {
+// attributesJSON replaces the custom attributes; empty keeps the current ones.
+func (s *SmartContract) UpdateAsset(ctx contractapi.TransactionContextInterface, id string, name string, assetType string, owner string, status string, metadataUrl string, metadataHash string, attributesJSON string) error {
 	exists, err := s.AssetExists(ctx, id)
 	if err != nil {
 		return err
@@ -246,13 +298,47 @@ func (s *SmartContract) UpdateAsset(ctx contractapi.TransactionContextInterface,
 	if err != nil {
 		return err
 	}
+	submitterID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	if oldAsset.Owner != submitterID {
+		if err := requireAdmin(ctx); err != nil {
+			return fmt.Errorf("only the owner or an admin can update asset %s. Owner: %s, Caller: %s", id, oldAsset.Owner, submitterID)
+		}
+	}
+	if err := requireNoTransferLock(oldAsset); err != nil {
+		return err
+	}
+	if err := requireNotArchived(oldAsset); err != nil {
+		return err
+	}
+	if err := requireNotFrozen(oldAsset); err != nil {
+		return err
+	}
+	if err := validateStatusTransition(oldAsset.Status, status); err != nil {
+		return err
+	}
+	// Ownership only changes through the multi-sig flow, shares or an audited admin override
+	if owner != oldAsset.Owner {
+		return fmt.Errorf("asset %s cannot change owner through UpdateAsset; use InitiateTransfer, TransferShares or AdminForceTransfer", id)
+	}
+
+	// Empty attributes keep the current ones ("{}" clears them); either way they must fit the (possibly new) type
+	attributes := oldAsset.Attributes
+	if attributesJSON != "" {
+		if attributes, err = parseAttributes(attributesJSON); err != nil {
+			return err
+		}
+	}
+	if err := validateAttributes(ctx, assetType, attributes); err != nil {
+		return err
+	}
 
 	timestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
 		return err
 	}
-	rawID, _ := ctx.GetClientIdentity().GetID()
-	submitterID := extractUsername(rawID)
 
 	asset := Asset{
 		DocType:        "asset",
@@ -263,46 +349,48 @@ func (s *SmartContract) UpdateAsset(ctx contractapi.TransactionContextInterface,
 		Status:         status,
 		MetadataURL:    metadataUrl,
 		MetadataHash:   metadataHash,
+		Attributes:     attributes,
 		Viewers:        oldAsset.Viewers,
+		Grants:         oldAsset.Grants,
 		UpdatedAt:      timestamp.Seconds,
 		LastModifiedBy: submitterID,
 		Sequence:       oldAsset.Sequence + 1,
-	}
-	assetJSON, err := json.Marshal(asset)
+		TotalUnits:     oldAsset.TotalUnits,
+		Shares:         oldAsset.Shares,
+		ParentID:       oldAsset.ParentID,
+		Children:       oldAsset.Children,
+		Custodian:      oldAsset.Custodian,
+		CustodySince:   oldAsset.CustodySince,
+		CustodyUntil:   oldAsset.CustodyUntil,
+		Attestations:   oldAsset.Attestations,
+		Freeze:         oldAsset.Freeze,
+		FreezeHistory:  oldAsset.FreezeHistory,
+	}
+	assetJSON, err := putAsset(ctx, &asset)
 	if err != nil {
 		return err
 	}
 
-	err = ctx.GetStub().PutState(id, assetJSON)
-	if err != nil {
-		return err
+	// A status change gets its own event so the transition is visible in the audit trail
+	if oldAsset.Status != status {
+		eventJSON, err := json.Marshal(AssetStatusChangedEvent{Asset: &asset, PreviousStatus: oldAsset.Status})
+		if err != nil {
+			return err
+		}
+		return ctx.GetStub().SetEvent("AssetStatusChanged", eventJSON)
 	}
 	return ctx.GetStub().SetEvent("AssetUpdated", assetJSON)
 }
 
 
-// DeleteAsset deletes an given asset from the world state.
-func (s *SmartContract) DeleteAsset(ctx contractapi.TransactionContextInterface, id string) error {
-	exists, err := s.AssetExists(ctx, id)
-	if err != nil {
-		return err
-	}
-	if !exists {
-		return fmt.Errorf("the asset %s does not exist", id)
-	}
-
-	err = ctx.GetStub().DelState(id)
-	if err != nil {
-		return err
-	}
-	// Event payload is just the ID for deletion
-	return ctx.GetStub().SetEvent("AssetDeleted", []byte(id))
-}
-
 
 // AssetExists returns true when asset with given ID exists in world state
 func (s *SmartContract) AssetExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
-	assetJSON, err := ctx.GetStub().GetState(id)
+	key, err := assetKey(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	assetJSON, err := ctx.GetStub().GetState(key)
 	if err != nil {
 		return false, fmt.Errorf("failed to read from world state: %v", err)
 	}
@@ -310,38 +398,71 @@ func (s *SmartContract) AssetExists(ctx contractapi.TransactionContextInterface,
 	return assetJSON != nil, nil
 }
 
-// GrantAccess adds a viewer to the asset
-func (s *SmartContract) GrantAccess(ctx contractapi.TransactionContextInterface, id string, viewerId string) error {
+// GrantAccess adds a viewer to the asset, or updates the grant of an existing viewer.
+// The viewer is a user ID, "EVERYONE", "role:<Role>" or "group:<name>".
+// permission is "metadata" or "full" (empty means full; EVERYONE is always metadata only).
+// durationSeconds limits the grant in time; 0 grants access until it is revoked.
+func (s *SmartContract) GrantAccess(ctx contractapi.TransactionContextInterface, id string, viewerId string, permission string, durationSeconds int64) error {
 	asset, err := s.ReadAsset(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	// Check for duplicates
-	for _, v := range asset.Viewers {
-		if v == viewerId {
-			return nil // Already granted
-		}
+	if err := requireNoTransferLock(asset); err != nil {
+		return err
+	}
+	if err := requireNotArchived(asset); err != nil {
+		return err
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return err
+	}
+
+	if viewerId == "" {
+		return fmt.Errorf("a viewer is required")
+	}
+	if err := validateViewer(ctx, viewerId); err != nil {
+		return err
+	}
+	if permission == "" {
+		permission = PermissionFull
+	}
+	if viewerId == publicViewer {
+		permission = PermissionMetadata
+	}
+	if err := validatePermission(permission); err != nil {
+		return err
+	}
+	if durationSeconds < 0 {
+		return fmt.Errorf("duration cannot be negative, got %d", durationSeconds)
 	}
 
 	timestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
 		return err
 	}
-	rawID, _ := ctx.GetClientIdentity().GetID()
-	submitterID := extractUsername(rawID)
+	submitterID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	if asset.Owner != submitterID {
+		return fmt.Errorf("only the owner can grant access to asset %s. Owner: %s, Caller: %s", id, asset.Owner, submitterID)
+	}
 
+	grant := &ViewerGrant{Viewer: viewerId, Permission: permission, GrantedBy: submitterID, GrantedAt: timestamp.Seconds}
+	if durationSeconds > 0 {
+		grant.ExpiresAt = timestamp.Seconds + durationSeconds
+	}
+
+	// Re-granting replaces the previous permission and expiry
+	removeViewer(asset, viewerId)
 	asset.Viewers = append(asset.Viewers, viewerId)
+	asset.Grants = append(asset.Grants, grant)
 	asset.UpdatedAt = timestamp.Seconds
 	asset.LastModifiedBy = submitterID
 	asset.Sequence = asset.Sequence + 1
 
-	assetJSON, err := json.Marshal(asset)
-	if err != nil {
-		return err
-	}
-
-	err = ctx.GetStub().PutState(id, assetJSON)
+	assetJSON, err := putAsset(ctx, asset)
 	if err != nil {
 		return err
 	}
@@ -356,31 +477,34 @@ func (s *SmartContract) RevokeAccess(ctx contractapi.TransactionContextInterface
 		return err
 	}
 
-	newViewers := []string{}
-	for _, v := range asset.Viewers {
-		if v != viewerId {
-			newViewers = append(newViewers, v)
-		}
+	if err := requireNoTransferLock(asset); err != nil {
+		return err
+	}
+	if err := requireNotArchived(asset); err != nil {
+		return err
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return err
 	}
 
 	timestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
 		return err
 	}
-	rawID, _ := ctx.GetClientIdentity().GetID()
-	submitterID := extractUsername(rawID)
+	submitterID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	if asset.Owner != submitterID {
+		return fmt.Errorf("only the owner can revoke access to asset %s. Owner: %s, Caller: %s", id, asset.Owner, submitterID)
+	}
 
-	asset.Viewers = newViewers
+	removeViewer(asset, viewerId)
 	asset.UpdatedAt = timestamp.Seconds
 	asset.LastModifiedBy = submitterID
 	asset.Sequence = asset.Sequence + 1
 
-	assetJSON, err := json.Marshal(asset)
-	if err != nil {
-		return err
-	}
-
-	err = ctx.GetStub().PutState(id, assetJSON)
+	assetJSON, err := putAsset(ctx, asset)
 	if err != nil {
 		return err
 	}
@@ -389,39 +513,120 @@ func (s *SmartContract) RevokeAccess(ctx contractapi.TransactionContextInterface
 
 // ========== MULTI-SIGNATURE TRANSFER FUNCTIONS ==========
 
-// InitiateTransfer creates a pending transfer requiring 2-party approval
-func (s *SmartContract) InitiateTransfer(ctx contractapi.TransactionContextInterface, assetID string, newOwner string, initiatorID string) error {
+// InitiateTransfer creates a pending transfer requiring approval under the asset's transfer policy
+// (2-party by default, see policy.go). expiresInSeconds picks the approval window within the
+// asset type's ExpiryWindow; 0 uses the type default. A positive price makes it a sale: the new
+// owner pays that many tokens to the current owner when the transfer executes.
+// The asset is locked until the transfer is executed, rejected, expired or invalidated.
+func (s *SmartContract) InitiateTransfer(ctx contractapi.TransactionContextInterface, assetID string, newOwner string, expiresInSeconds int64, price int64) (*PendingTransfer, error) {
+	initiatorID, err := activeCallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get the asset
 	asset, err := s.ReadAsset(ctx, assetID)
 	if err != nil {
-		return fmt.Errorf("asset not found: %v", err)
+		return nil, fmt.Errorf("asset not found: %v", err)
 	}
 
 	// Verify initiator is current owner
 	if asset.Owner != initiatorID {
-		return fmt.Errorf("only asset owner can initiate transfer. Owner: %s, Initiator: %s", asset.Owner, initiatorID)
+		return nil, fmt.Errorf("only asset owner can initiate transfer. Owner: %s, Initiator: %s", asset.Owner, initiatorID)
+	}
+
+	if err := requireNoTransferLock(asset); err != nil {
+		return nil, err
+	}
+	if err := requireTransferable(asset); err != nil {
+		return nil, err
+	}
+	if err := requireSoleHolder(asset, initiatorID); err != nil {
+		return nil, err
+	}
+	if err := requireNotAttached(asset); err != nil {
+		return nil, err
+	}
+	if err := requireNotOnLoan(asset); err != nil {
+		return nil, err
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return nil, err
+	}
+
+	// Attached assets travel with the parent, so each of them must be transferable too
+	bundled, _, err := s.descendantsOf(ctx, asset)
+	if err != nil {
+		return nil, err
+	}
+	bundledIDs := []string{}
+	for _, child := range bundled {
+		if child.Owner != initiatorID {
+			return nil, fmt.Errorf("attached asset %s is owned by %s, not %s", child.ID, child.Owner, initiatorID)
+		}
+		if err := requireNoTransferLock(child); err != nil {
+			return nil, err
+		}
+		if err := requireTransferable(child); err != nil {
+			return nil, err
+		}
+		if err := requireNotOnLoan(child); err != nil {
+			return nil, err
+		}
+		if err := requireNotFrozen(child); err != nil {
+			return nil, err
+		}
+		bundledIDs = append(bundledIDs, child.ID)
 	}
 
 	// Cannot transfer to self
 	if newOwner == initiatorID {
-		return fmt.Errorf("cannot transfer asset to yourself")
+		return nil, fmt.Errorf("cannot transfer asset to yourself")
+	}
+	if price < 0 {
+		return nil, fmt.Errorf("the price cannot be negative, got %d", price)
+	}
+
+	// Locked users cannot receive assets
+	if err := requireUnlocked(ctx, newOwner); err != nil {
+		return nil, err
 	}
 
 	// Check if pending transfer already exists
-	pendingKey := fmt.Sprintf("PENDING_TRANSFER_%s", assetID)
+	pendingKey, err := transferKey(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
 	existingBytes, err := ctx.GetStub().GetState(pendingKey)
 	if err == nil && existingBytes != nil {
 		var existing PendingTransfer
 		json.Unmarshal(existingBytes, &existing)
-		if existing.Status == "PENDING" {
-			return fmt.Errorf("a pending transfer already exists for this asset")
+		if existing.Status == TransferStatusPending {
+			return nil, fmt.Errorf("a pending transfer already exists for this asset")
 		}
 	}
 
+	// Snapshot the approval policy so later policy changes do not affect this transfer
+	policy, err := effectiveTransferPolicy(ctx, asset)
+	if err != nil {
+		return nil, err
+	}
+	policy = policyForTransfer(policy, initiatorID, newOwner)
+
+	// Approval deadline: requested window (0 = type default) bounded by the asset type's limits
+	window, err := readExpiryWindow(ctx, asset.Type)
+	if err != nil {
+		return nil, err
+	}
+	expirySeconds, err := resolveExpirySeconds(window, expiresInSeconds)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create pending transfer with auto-approval from initiator
 	timestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
-		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+		return nil, fmt.Errorf("failed to get transaction timestamp: %v", err)
 	}
 	now := timestamp.Seconds
 	
@@ -431,183 +636,244 @@ func (s *SmartContract) InitiateTransfer(ctx contractapi.TransactionContextInter
 		AssetName:    asset.Name,
 		CurrentOwner: initiatorID,
 		NewOwner:     newOwner,
-		Status:       "PENDING",
+		Status:       TransferStatusPending,
 		Approvals: []Approval{
 			{
 				Signer:    initiatorID,
-				Role:      "CURRENT_OWNER",
+				Role:      ApprovalRoleCurrentOwner,
 				Timestamp: now,
 				Comment:   "Initiated transfer",
 			},
 		},
 		CreatedAt: now,
-		ExpiresAt: now + 86400, // 24 hours in seconds
+		ExpiresAt: now + expirySeconds,
+		Policy:            policy,
+		RequiredApprovals: policy.Threshold,
+		Price:             price,
+	}
+	if len(bundledIDs) > 0 {
+		pendingTransfer.BundledAssets = bundledIDs
 	}
 
 	// Store pending transfer on blockchain
-	pendingJSON, err := json.Marshal(pendingTransfer)
-	if err != nil {
-		return fmt.Errorf("failed to marshal pending transfer: %v", err)
+	if _, err := putPendingTransfer(ctx, &pendingTransfer); err != nil {
+		return nil, err
 	}
 
-	err = ctx.GetStub().PutState(pendingKey, pendingJSON)
-	if err != nil {
-		return fmt.Errorf("failed to store pending transfer: %v", err)
+	// Lock the asset and its attached assets so no other mutation can race the approval
+	if err := lockAssetForTransfer(ctx, asset, initiatorID, now); err != nil {
+		return nil, err
+	}
+	for _, child := range bundled {
+		if err := lockAssetForTransfer(ctx, child, initiatorID, now); err != nil {
+			return nil, err
+		}
 	}
 
 	// Emit event
-	ctx.GetStub().SetEvent("TransferInitiated", pendingJSON)
-
-	return nil
+	if err := emitTransferEvent(ctx, "TransferInitiated", &pendingTransfer, asset, bundled...); err != nil {
+		return nil, err
+	}
+	return &pendingTransfer, nil
 }
 
-// ApproveTransfer approves a pending transfer and executes if 2/2 signatures collected
-func (s *SmartContract) ApproveTransfer(ctx contractapi.TransactionContextInterface, assetID string, approverID string) error {
+// ApproveTransfer records an approval and executes the transfer once its policy is satisfied.
+// It returns the transfer with its resulting status. Expired or invalid transfers are
+// recorded (and the asset lock released) instead of failing, so that state is committed.
+func (s *SmartContract) ApproveTransfer(ctx contractapi.TransactionContextInterface, assetID string) (*PendingTransfer, error) {
+	approverID, err := activeCallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get pending transfer
-	pendingKey := fmt.Sprintf("PENDING_TRANSFER_%s", assetID)
-	pendingBytes, err := ctx.GetStub().GetState(pendingKey)
-	if err != nil || pendingBytes == nil {
-		return fmt.Errorf("pending transfer not found for asset: %s", assetID)
+	pendingKey, err := transferKey(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.GetPendingTransfer(ctx, assetID)
+	if err != nil {
+		return nil, err
 	}
 
-	var pending PendingTransfer
-	err = json.Unmarshal(pendingBytes, &pending)
+	// Check if already executed/rejected
+	if pending.Status != TransferStatusPending {
+		return nil, fmt.Errorf("transfer is no longer pending. Status: %s", pending.Status)
+	}
+
+	// Verify approver is the recipient or a co-signer named by the policy
+	approvalRole, err := approvalRoleFor(ctx, pending, approverID)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal pending transfer: %v", err)
+		return nil, err
 	}
 
 	// Check expiration
 	timestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
-		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+		return nil, fmt.Errorf("failed to get transaction timestamp: %v", err)
 	}
 	now := timestamp.Seconds
 	
 	if now > pending.ExpiresAt {
-		pending.Status = "EXPIRED"
-		pendingJSON, _ := json.Marshal(pending)
-		ctx.GetStub().PutState(pendingKey, pendingJSON)
-		ctx.GetStub().SetEvent("TransferExpired", pendingJSON)
-		return fmt.Errorf("transfer request has expired")
-	}
-
-	// Check if already executed/rejected
-	if pending.Status != "PENDING" {
-		return fmt.Errorf("transfer is no longer pending. Status: %s", pending.Status)
-	}
-
-	// Verify approver is the new owner (recipient)
-	if approverID != pending.NewOwner {
-		return fmt.Errorf("only the recipient can approve. Expected: %s, Got: %s", pending.NewOwner, approverID)
-	}
-
-	// Check if already approved by this user
-	for _, approval := range pending.Approvals {
-		if approval.Signer == approverID {
-			return fmt.Errorf("you have already approved this transfer")
-		}
+		return s.closeTransfer(ctx, pending, TransferStatusExpired, "TransferExpired", approverID, now)
 	}
 
 	// Add approval
 	pending.Approvals = append(pending.Approvals, Approval{
 		Signer:    approverID,
-		Role:      "NEW_OWNER",
+		Role:      approvalRole,
 		Timestamp: now,
 		Comment:   "Approved transfer",
 	})
 
-	// Check if we have 2 approvals - EXECUTE TRANSFER
-	if len(pending.Approvals) >= 2 {
+	// Check if the policy is met (recipient signed and threshold reached) - EXECUTE TRANSFER
+	if transferPolicySatisfied(pending) {
 		// Re-read asset to verify ownership hasn't changed
+		exists, err := s.AssetExists(ctx, assetID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return s.closeTransfer(ctx, pending, TransferStatusInvalid, "TransferInvalidated", approverID, now)
+		}
 		asset, err := s.ReadAsset(ctx, assetID)
 		if err != nil {
-			pending.Status = "INVALID"
-			pendingJSON, _ := json.Marshal(pending)
-			ctx.GetStub().PutState(pendingKey, pendingJSON)
-			return fmt.Errorf("asset no longer exists: %v", err)
+			return nil, err
 		}
 
 		// Verify current owner matches pending transfer
 		if asset.Owner != pending.CurrentOwner {
-			pending.Status = "INVALID"
-			pendingJSON, _ := json.Marshal(pending)
-			ctx.GetStub().PutState(pendingKey, pendingJSON)
-			return fmt.Errorf("asset owner has changed. Expected: %s, Current: %s", pending.CurrentOwner, asset.Owner)
+			return s.closeTransfer(ctx, pending, TransferStatusInvalid, "TransferInvalidated", approverID, now)
+		}
+
+		// The owner may have been locked since the transfer was initiated
+		if err := requireUnlocked(ctx, pending.CurrentOwner); err != nil {
+			return nil, err
+		}
+
+		// Transfers initiated before assets were locked still need a transferable status
+		if asset.Status != AssetStatusPendingTransfer {
+			if err := requireTransferable(asset); err != nil {
+				return nil, err
+			}
+		}
+
+		// Asset types may require a valid auditor attestation before the asset changes hands
+		if err := requireAttestations(ctx, asset, now); err != nil {
+			return nil, err
+		}
+
+		// Delivery versus payment: the buyer pays in this transaction, and an
+		// insufficient balance fails the approval so the asset does not move either
+		payment, err := settlePayment(ctx, pending, approverID, now)
+		if err != nil {
+			return nil, err
 		}
 
 		// ATOMIC TRANSFER EXECUTION
-		// Update UpdatedAt, LastModifiedBy, Sequence
-		rawID, _ := ctx.GetClientIdentity().GetID()
-		submitterID := extractUsername(rawID)
-		asset.Owner = pending.NewOwner
+		// Update UpdatedAt, LastModifiedBy, Sequence and release the transfer lock
+		assignOwner(asset, pending.NewOwner)
+		if asset.Status == AssetStatusPendingTransfer {
+			restoreStatusAfterTransfer(asset)
+		}
 		asset.UpdatedAt = now // 'now' is already defined from Timestamp
-		asset.LastModifiedBy = submitterID // Usually the new owner executing
+		asset.LastModifiedBy = approverID // The approval that satisfied the policy executes the transfer
 		asset.Sequence = asset.Sequence + 1
 
-		assetJSON, err := json.Marshal(asset)
-		if err != nil {
-			return fmt.Errorf("failed to marshal asset: %v", err)
+		if _, err := putAsset(ctx, asset); err != nil {
+			return nil, fmt.Errorf("failed to update asset ownership: %v", err)
 		}
 
-		err = ctx.GetStub().PutState(assetID, assetJSON)
+		// Attached assets change hands in the same transaction
+		children, err := s.moveBundledAssets(ctx, pending, approverID, now)
 		if err != nil {
-			return fmt.Errorf("failed to update asset ownership: %v", err)
+			return nil, err
 		}
 
 		// Mark pending transfer as executed
-		pending.Status = "EXECUTED"
+		pending.Status = TransferStatusExecuted
 		pending.ExecutedAt = now
 
-		// Emit transfer event
-		ctx.GetStub().SetEvent("AssetTransferred", assetJSON)
-
 		// Delete pending transfer (cleanup)
 		err = ctx.GetStub().DelState(pendingKey)
 		if err != nil {
-			return fmt.Errorf("failed to delete pending transfer: %v", err)
+			return nil, fmt.Errorf("failed to delete pending transfer: %v", err)
 		}
 
-		// Emit execution event
-		executedJSON, _ := json.Marshal(pending)
-		ctx.GetStub().SetEvent("TransferExecuted", executedJSON)
-
-		return nil
+		// Emit execution event (carries the new asset state and the payment for sync)
+		if err := setTransferEvent(ctx, "TransferExecuted", TransferEvent{PendingTransfer: pending, Asset: asset, Children: children, Payment: payment}); err != nil {
+			return nil, err
+		}
+		return pending, nil
 	}
 
 	// Not enough approvals yet, update pending transfer
-	pendingJSON, err := json.Marshal(pending)
-	if err != nil {
-		return fmt.Errorf("failed to marshal pending transfer: %v", err)
+	if _, err := putPendingTransfer(ctx, pending); err != nil {
+		return nil, err
+	}
+
+	// Emit approval event
+	if err := emitTransferEvent(ctx, "TransferApproved", pending, nil); err != nil {
+		return nil, err
 	}
+	return pending, nil
+}
 
-	err = ctx.GetStub().PutState(pendingKey, pendingJSON)
+// closeTransfer ends a pending transfer without executing it, releases the asset lock
+// and emits the matching event
+func (s *SmartContract) closeTransfer(ctx contractapi.TransactionContextInterface, pending *PendingTransfer, status string, eventName string, actorID string, now int64) (*PendingTransfer, error) {
+	asset, children, err := s.endTransfer(ctx, pending, status, actorID, now)
 	if err != nil {
-		return fmt.Errorf("failed to update pending transfer: %v", err)
+		return nil, err
 	}
 
-	// Emit approval event
-	ctx.GetStub().SetEvent("TransferApproved", pendingJSON)
+	if err := emitTransferEvent(ctx, eventName, pending, asset, children...); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
 
-	return nil
+// endTransfer records the final status of a pending transfer and releases the asset lock,
+// including the locks on bundled assets. It returns the released asset (nil if it was not locked)
+// and the released bundled assets, and emits no event.
+func (s *SmartContract) endTransfer(ctx contractapi.TransactionContextInterface, pending *PendingTransfer, status string, actorID string, now int64) (*Asset, []*Asset, error) {
+	pending.Status = status
+	if _, err := putPendingTransfer(ctx, pending); err != nil {
+		return nil, nil, err
+	}
+	asset, err := s.releaseTransferLock(ctx, pending.AssetID, actorID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	children := []*Asset{}
+	for _, childID := range pending.BundledAssets {
+		child, err := s.releaseTransferLock(ctx, childID, actorID, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		if child != nil {
+			children = append(children, child)
+		}
+	}
+	return asset, children, nil
 }
 
 // RejectTransfer rejects a pending transfer
-func (s *SmartContract) RejectTransfer(ctx contractapi.TransactionContextInterface, assetID string, reason string, rejectorID string) error {
-	// Get pending transfer
-	pendingKey := fmt.Sprintf("PENDING_TRANSFER_%s", assetID)
-	pendingBytes, err := ctx.GetStub().GetState(pendingKey)
-	if err != nil || pendingBytes == nil {
-		return fmt.Errorf("pending transfer not found for asset: %s", assetID)
+func (s *SmartContract) RejectTransfer(ctx contractapi.TransactionContextInterface, assetID string, reason string) error {
+	rejectorID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
 	}
 
-	var pending PendingTransfer
-	err = json.Unmarshal(pendingBytes, &pending)
+	// Get pending transfer
+	pending, err := s.GetPendingTransfer(ctx, assetID)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal pending transfer: %v", err)
+		return err
 	}
 
 	// Check if already executed/rejected
-	if pending.Status != "PENDING" {
+	if pending.Status != TransferStatusPending {
 		return fmt.Errorf("transfer is no longer pending. Status: %s", pending.Status)
 	}
 
@@ -616,30 +882,23 @@ func (s *SmartContract) RejectTransfer(ctx contractapi.TransactionContextInterfa
 		return fmt.Errorf("only involved parties can reject. Rejector: %s", rejectorID)
 	}
 
-	// Mark as rejected
-	pending.Status = "REJECTED"
-	pending.RejectionReason = reason
-
-	pendingJSON, err := json.Marshal(pending)
-	if err != nil {
-		return fmt.Errorf("failed to marshal pending transfer: %v", err)
-	}
-
-	// Update state (keep for audit trail)
-	err = ctx.GetStub().PutState(pendingKey, pendingJSON)
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
-		return fmt.Errorf("failed to update pending transfer: %v", err)
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
 	}
 
-	// Emit rejection event
-	ctx.GetStub().SetEvent("TransferRejected", pendingJSON)
-
-	return nil
+	// Mark as rejected (kept for audit trail) and release the asset
+	pending.RejectionReason = reason
+	_, err = s.closeTransfer(ctx, pending, TransferStatusRejected, "TransferRejected", rejectorID, timestamp.Seconds)
+	return err
 }
 
 // GetPendingTransfer retrieves a pending transfer by asset ID
 func (s *SmartContract) GetPendingTransfer(ctx contractapi.TransactionContextInterface, assetID string) (*PendingTransfer, error) {
-	pendingKey := fmt.Sprintf("PENDING_TRANSFER_%s", assetID)
+	pendingKey, err := transferKey(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
 	pendingBytes, err := ctx.GetStub().GetState(pendingKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read pending transfer: %v", err)
@@ -659,11 +918,8 @@ func (s *SmartContract) GetPendingTransfer(ctx contractapi.TransactionContextInt
 
 // GetAllPendingTransfers returns all pending transfers
 func (s *SmartContract) GetAllPendingTransfers(ctx contractapi.TransactionContextInterface) ([]*PendingTransfer, error) {
-	// Use GetStateByRange to find all keys starting with PENDING_TRANSFER_
-	startKey := "PENDING_TRANSFER_"
-	endKey := "PENDING_TRANSFER_\uffff"
-	
-	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
+	// All pending transfers share the transfer composite key namespace
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(transferObjectType, []string{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pending transfers: %v", err)
 	}
@@ -683,7 +939,7 @@ func (s *SmartContract) GetAllPendingTransfers(ctx contractapi.TransactionContex
 		}
 
 		// Only return PENDING status
-		if pending.Status == "PENDING" {
+		if pending.Status == TransferStatusPending {
 			pendingTransfers = append(pendingTransfers, &pending)
 		}
 	}
@@ -691,68 +947,10 @@ func (s *SmartContract) GetAllPendingTransfers(ctx contractapi.TransactionContex
 	return pendingTransfers, nil
 }
 
-// Helper function to extract username from client ID
-func extractUsername(clientID string) string {
-	// Client ID format: x509::/CN=username::OU=client::...
-	// Extract the CN (Common Name) which is the username
-	start := len("x509::/CN=")
-	end := len(clientID)
-	for i := start; i < len(clientID); i++ {
-		if clientID[i] == ':' {
-			end = i
-			break
-		}
-	}
-	if end > start && end <= len(clientID) {
-		return clientID[start:end]
-	}
-	return clientID // Fallback to full ID if parsing fails
-}
-
-
-// TransferAsset updates the owner field of asset with given id in world state.
-// NOTE: This function is now DEPRECATED in favor of multi-sig transfers
-// It remains for backward compatibility and admin override scenarios
-
-func (s *SmartContract) TransferAsset(ctx contractapi.TransactionContextInterface, id string, newOwner string) error {
-	asset, err := s.ReadAsset(ctx, id)
-	if err != nil {
-		return err
-	}
-
-	// Optional: Add logic here (e.g., check if Status == "Available" before transfer)
-	// For now, we allow transfer regardless of status, but we could enforce it.
-	// if asset.Status != "Available" { return fmt.Errorf("Asset is %s, cannot transfer", asset.Status) }
-
-	timestamp, err := ctx.GetStub().GetTxTimestamp()
-	if err != nil {
-		return err
-	}
-	rawID, _ := ctx.GetClientIdentity().GetID()
-	submitterID := extractUsername(rawID)
-
-	asset.Owner = newOwner
-	asset.UpdatedAt = timestamp.Seconds
-	asset.LastModifiedBy = submitterID
-	asset.Sequence = asset.Sequence + 1
-
-	assetJSON, err := json.Marshal(asset)
-	if err != nil {
-		return err
-	}
-
-	err = ctx.GetStub().PutState(id, assetJSON)
-	if err != nil {
-		return err
-	}
-	return ctx.GetStub().SetEvent("AssetTransferred", assetJSON)
-}
-
-
 // GetAllAssets returns all assets found in world state
 func (s *SmartContract) GetAllAssets(ctx contractapi.TransactionContextInterface) ([]*Asset, error) {
-	// range query with empty string for startKey and endKey does an open-ended query of all assets in the chaincode namespace.
-	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	// partial composite key query over the asset namespace only returns assets
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(assetObjectType, []string{})
 	if err != nil {
 		return nil, err
 	}
@@ -770,19 +968,41 @@ func (s *SmartContract) GetAllAssets(ctx contractapi.TransactionContextInterface
 		if err != nil {
 			return nil, err
 		}
-		
-		// FILTER: Only return records where DocType is "asset"
-		if asset.DocType == "asset" {
-			assets = append(assets, &asset)
-		}
+		assets = append(assets, &asset)
 	}
 	return assets, nil
 }
 
 // CreateUser registers a new user in the system (On-Chain Identity only)
 func (s *SmartContract) CreateUser(ctx contractapi.TransactionContextInterface, id string, role string) error {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Anyone may self-register as a plain user, but only under their own ID;
+	// registering others and granting privileged roles is for admins only
+	switch role {
+	case RoleUser:
+		if id != callerID {
+			if err := requireAdmin(ctx); err != nil {
+				return fmt.Errorf("users can only register themselves. Caller: %s, ID: %s", callerID, id)
+			}
+		}
+	case RoleAdmin, RoleAuditor:
+		if err := requireAdmin(ctx); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid role %s", role)
+	}
+
 	// Check if user already exists
-	userJSON, err := ctx.GetStub().GetState(id)
+	key, err := userKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	userJSON, err := ctx.GetStub().GetState(key)
 	if err != nil {
 		return fmt.Errorf("failed to read from world state: %v", err)
 	}
@@ -799,17 +1019,12 @@ func (s *SmartContract) CreateUser(ctx contractapi.TransactionContextInterface,
 		DocType:   "user",
 		ID:        id,
 		Role:      role,
-		Status:    "Active",
+		Status:    UserStatusActive,
 		UpdatedAt: timestamp.Seconds,
 		Sequence:  1,
 	}
 	
-	userBytes, err := json.Marshal(user)
-	if err != nil {
-		return err
-	}
-
-	err = ctx.GetStub().PutState(id, userBytes)
+	userBytes, err := putUser(ctx, &user)
 	if err != nil {
 		return err
 	}
@@ -819,7 +1034,11 @@ func (s *SmartContract) CreateUser(ctx contractapi.TransactionContextInterface,
 
 // ReadUser returns the user stored in the world state with given id.
 func (s *SmartContract) ReadUser(ctx contractapi.TransactionContextInterface, id string) (*User, error) {
-	userJSON, err := ctx.GetStub().GetState(id)
+	key, err := userKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	userJSON, err := ctx.GetStub().GetState(key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from world state: %v", err)
 	}
@@ -838,7 +1057,11 @@ func (s *SmartContract) ReadUser(ctx contractapi.TransactionContextInterface, id
 
 // GetAssetHistory returns the chain of custody for an asset since issuance.
 func (s *SmartContract) GetAssetHistory(ctx contractapi.TransactionContextInterface, assetID string) ([]HistoryQueryResult, error) {
-	resultsIterator, err := ctx.GetStub().GetHistoryForKey(assetID)
+	key, err := assetKey(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(key)
 	if err != nil {
 		return nil, err
 	}
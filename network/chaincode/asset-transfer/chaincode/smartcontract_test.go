@@ -0,0 +1,151 @@
+package chaincode
+
+import "testing"
+
+func TestCreateUserAuthorization(t *testing.T) {
+	tests := []struct {
+		name       string
+		callerID   string
+		callerRole string
+		id         string
+		role       string
+		wantErr    bool
+	}{
+		{"user registers themselves", "alice", RoleUser, "alice", RoleUser, false},
+		{"user registers someone else", "alice", RoleUser, "bob", RoleUser, true},
+		{"caller without role registers someone else", "alice", "", "bob", RoleUser, true},
+		{"user grants themselves admin", "alice", RoleUser, "alice", RoleAdmin, true},
+		{"user grants themselves auditor", "alice", RoleUser, "alice", RoleAuditor, true},
+		{"admin registers another user", "admin", RoleAdmin, "bob", RoleUser, false},
+		{"admin registers an auditor", "admin", RoleAdmin, "carol", RoleAuditor, false},
+		{"unknown role", "admin", RoleAdmin, "bob", "Owner", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := newTestLedger(t)
+			err := (&SmartContract{}).CreateUser(ledger.as(tt.callerID, tt.callerRole), tt.id, tt.role)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateUser(%q, %q) as %s error = %v, wantErr %v", tt.id, tt.role, tt.callerID, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateUserRefusesDuplicatesAndLockedCallers(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.seedUsers(map[string]string{"alice": RoleUser})
+	contract := &SmartContract{}
+
+	if err := contract.CreateUser(ledger.as("alice", RoleUser), "alice", RoleUser); err == nil {
+		t.Fatal("CreateUser re-registered an existing user")
+	}
+
+	if err := contract.SetUserStatus(ledger.as("admin", RoleAdmin), "alice", UserStatusLocked); err != nil {
+		t.Fatalf("SetUserStatus: %v", err)
+	}
+	if err := contract.CreateUser(ledger.as("alice", RoleAdmin), "dave", RoleUser); err == nil {
+		t.Fatal("a locked caller registered a user")
+	}
+}
+
+func TestTransferLocksAssetUntilApproved(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.seedUsers(map[string]string{"alice": RoleUser, "bob": RoleUser, "mallory": RoleUser})
+	ledger.seedAsset(&Asset{ID: "asset1", Name: "Car", Type: "Vehicle", Owner: "alice", Status: AssetStatusAvailable, Sequence: 1})
+	contract := &SmartContract{}
+
+	if _, err := contract.InitiateTransfer(ledger.as("mallory", RoleUser), "asset1", "bob", 0, 0); err == nil {
+		t.Fatal("a non-owner initiated a transfer")
+	}
+	if _, err := contract.InitiateTransfer(ledger.as("alice", RoleUser), "asset1", "alice", 0, 0); err == nil {
+		t.Fatal("the owner initiated a transfer to themselves")
+	}
+
+	if _, err := contract.InitiateTransfer(ledger.as("alice", RoleUser), "asset1", "bob", 0, 0); err != nil {
+		t.Fatalf("InitiateTransfer: %v", err)
+	}
+	if got := ledger.asset("asset1"); got.Status != AssetStatusPendingTransfer || got.StatusBeforeTransfer != AssetStatusAvailable {
+		t.Fatalf("after initiation: status = %q (before %q), want locked from %q", got.Status, got.StatusBeforeTransfer, AssetStatusAvailable)
+	}
+
+	// While locked, neither a second transfer nor an update may touch the asset
+	if _, err := contract.InitiateTransfer(ledger.as("alice", RoleUser), "asset1", "mallory", 0, 0); err == nil {
+		t.Fatal("a second transfer was initiated on a locked asset")
+	}
+	if err := contract.UpdateAsset(ledger.as("alice", RoleUser), "asset1", "Renamed", "Vehicle", "alice", AssetStatusAvailable, "", "", ""); err == nil {
+		t.Fatal("a locked asset was updated")
+	}
+
+	// Only the recipient may add the missing approval, and only once the initiator has signed
+	if _, err := contract.ApproveTransfer(ledger.as("mallory", RoleUser), "asset1"); err == nil {
+		t.Fatal("an outsider approved the transfer")
+	}
+	if _, err := contract.ApproveTransfer(ledger.as("alice", RoleUser), "asset1"); err == nil {
+		t.Fatal("the initiator approved twice")
+	}
+	pending, err := contract.ApproveTransfer(ledger.as("bob", RoleUser), "asset1")
+	if err != nil {
+		t.Fatalf("ApproveTransfer: %v", err)
+	}
+	if pending.Status != TransferStatusExecuted {
+		t.Fatalf("transfer status = %q, want %q", pending.Status, TransferStatusExecuted)
+	}
+	if got := ledger.asset("asset1"); got.Owner != "bob" || got.Status == AssetStatusPendingTransfer {
+		t.Fatalf("after approval: owner = %q, status = %q, want bob and unlocked", got.Owner, got.Status)
+	}
+}
+
+func TestRejectTransferReleasesLock(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.seedUsers(map[string]string{"alice": RoleUser, "bob": RoleUser})
+	ledger.seedAsset(&Asset{ID: "asset1", Name: "Car", Type: "Vehicle", Owner: "alice", Status: AssetStatusOwned, Sequence: 1})
+	contract := &SmartContract{}
+
+	if _, err := contract.InitiateTransfer(ledger.as("alice", RoleUser), "asset1", "bob", 0, 0); err != nil {
+		t.Fatalf("InitiateTransfer: %v", err)
+	}
+	if err := contract.RejectTransfer(ledger.as("bob", RoleUser), "asset1", "not interested"); err != nil {
+		t.Fatalf("RejectTransfer: %v", err)
+	}
+	if got := ledger.asset("asset1"); got.Owner != "alice" || got.Status != AssetStatusOwned {
+		t.Fatalf("after rejection: owner = %q, status = %q, want alice and %q", got.Owner, got.Status, AssetStatusOwned)
+	}
+	if _, err := contract.InitiateTransfer(ledger.as("alice", RoleUser), "asset1", "bob", 0, 0); err != nil {
+		t.Fatalf("InitiateTransfer after rejection: %v", err)
+	}
+}
+
+func TestUpdateAssetAuthorization(t *testing.T) {
+	tests := []struct {
+		name       string
+		callerID   string
+		callerRole string
+		owner      string
+		wantErr    bool
+	}{
+		{"owner", "alice", RoleUser, "alice", false},
+		{"admin", "admin", RoleAdmin, "alice", false},
+		{"stranger", "mallory", RoleUser, "alice", true},
+		{"owner hands the asset over", "alice", RoleUser, "bob", true},
+		{"admin reassigns the owner", "admin", RoleAdmin, "bob", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := newTestLedger(t)
+			ledger.seedUsers(map[string]string{"alice": RoleUser, "bob": RoleUser, "mallory": RoleUser})
+			ledger.seedAsset(&Asset{ID: "asset1", Name: "Car", Type: "Vehicle", Owner: "alice", Status: AssetStatusOwned, Sequence: 1})
+
+			err := (&SmartContract{}).UpdateAsset(ledger.as(tt.callerID, tt.callerRole), "asset1", "Renamed", "Vehicle", tt.owner, AssetStatusOwned, "", "", "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateAsset() as %s error = %v, wantErr %v", tt.callerID, err, tt.wantErr)
+			}
+			got := ledger.asset("asset1")
+			if got.Owner != "alice" {
+				t.Fatalf("owner = %q, want alice", got.Owner)
+			}
+			if renamed := got.Name == "Renamed"; renamed == tt.wantErr {
+				t.Fatalf("renamed = %v, wantErr %v", renamed, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Asset statuses
+const (
+	AssetStatusAvailable        = "Available"         // Listed and transferable
+	AssetStatusOwned            = "Owned"             // Held by the owner, transferable
+	AssetStatusSold             = "Sold"              // Sale agreed, awaiting handover to the new owner
+	AssetStatusLocked           = "Locked"            // Frozen by the owner, not transferable
+	AssetStatusUnderMaintenance = "Under Maintenance" // Temporarily out of service, not transferable
+	AssetStatusPendingTransfer  = "Pending Transfer"  // Locked by a multi-sig transfer, set and cleared by the transfer flow only
+	AssetStatusArchived         = "Archived"          // Soft-deleted, set by ArchiveAsset and cleared by RestoreAsset only (see archive.go)
+)
+
+// assetStatusTransitions lists, for every valid status, the statuses it may move to.
+// Pending Transfer and Archived are deliberately absent: they cannot be set or left through UpdateAsset.
+var assetStatusTransitions = map[string][]string{
+	AssetStatusAvailable:        {AssetStatusOwned, AssetStatusSold, AssetStatusLocked, AssetStatusUnderMaintenance},
+	AssetStatusOwned:            {AssetStatusAvailable, AssetStatusSold, AssetStatusLocked, AssetStatusUnderMaintenance},
+	AssetStatusSold:             {AssetStatusOwned},
+	AssetStatusLocked:           {AssetStatusAvailable, AssetStatusOwned},
+	AssetStatusUnderMaintenance: {AssetStatusAvailable, AssetStatusOwned, AssetStatusLocked},
+}
+
+// assetStatusOrder keeps query results stable (map iteration order is random,
+// which would make endorsement results differ between peers)
+var assetStatusOrder = []string{
+	AssetStatusAvailable,
+	AssetStatusOwned,
+	AssetStatusSold,
+	AssetStatusLocked,
+	AssetStatusUnderMaintenance,
+	AssetStatusPendingTransfer,
+	AssetStatusArchived,
+}
+
+// transferableStatuses are the statuses an asset must be in to change owner
+var transferableStatuses = map[string]bool{
+	AssetStatusAvailable: true,
+	AssetStatusOwned:     true,
+}
+
+// StatusRule describes one status of the asset state machine
+type StatusRule struct {
+	Status        string   `json:"status"`
+	Transferable  bool     `json:"transferable"`
+	AllowedNext   []string `json:"allowedNext"`
+	SystemManaged bool     `json:"systemManaged"` // Only the chaincode may enter or leave this status
+}
+
+// AssetStatusChangedEvent is the payload of the AssetStatusChanged event.
+// It embeds the full asset so off-chain sync can treat it like any asset event.
+type AssetStatusChangedEvent struct {
+	*Asset
+	PreviousStatus string `json:"previousStatus"`
+}
+
+// validateAssetStatus fails if the status is not part of the state machine
+func validateAssetStatus(status string) error {
+	if status == AssetStatusPendingTransfer {
+		return fmt.Errorf("status %q is managed by the transfer flow and cannot be set directly", status)
+	}
+	if status == AssetStatusArchived {
+		return fmt.Errorf("status %q is set by ArchiveAsset and cleared by RestoreAsset only", status)
+	}
+	if _, ok := assetStatusTransitions[status]; !ok {
+		return fmt.Errorf("invalid asset status %q. Valid statuses: %v", status, assetStatusOrder)
+	}
+	return nil
+}
+
+// validateStatusTransition fails unless the asset may move from one status to the other
+func validateStatusTransition(from string, to string) error {
+	if from == to {
+		return nil
+	}
+	if from == AssetStatusPendingTransfer {
+		return fmt.Errorf("asset is locked by a pending transfer; approve, reject or cancel the transfer first")
+	}
+	if from == AssetStatusArchived {
+		return fmt.Errorf("asset is archived; an admin must restore it first")
+	}
+	if err := validateAssetStatus(to); err != nil {
+		return err
+	}
+	// Records written before the state machine existed may hold free-form statuses;
+	// they can move to any valid status once
+	if _, known := assetStatusTransitions[from]; !known {
+		return nil
+	}
+	for _, next := range assetStatusTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid status transition from %s to %s. Allowed: %v", from, to, assetStatusTransitions[from])
+}
+
+// requireTransferable fails if the asset's status does not allow a change of owner
+func requireTransferable(asset *Asset) error {
+	if !transferableStatuses[asset.Status] {
+		return fmt.Errorf("asset %s is %s and cannot be transferred", asset.ID, asset.Status)
+	}
+	return nil
+}
+
+// GetAssetStatusRules returns the asset status state machine so clients can validate early
+func (s *SmartContract) GetAssetStatusRules(ctx contractapi.TransactionContextInterface) ([]*StatusRule, error) {
+	rules := []*StatusRule{}
+	for _, status := range assetStatusOrder {
+		allowedNext := assetStatusTransitions[status]
+		if allowedNext == nil {
+			allowedNext = []string{}
+		}
+		rules = append(rules, &StatusRule{
+			Status:        status,
+			Transferable:  transferableStatuses[status],
+			AllowedNext:   allowedNext,
+			SystemManaged: status == AssetStatusPendingTransfer || status == AssetStatusArchived,
+		})
+	}
+	return rules, nil
+}
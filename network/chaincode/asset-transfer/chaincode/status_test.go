@@ -0,0 +1,58 @@
+package chaincode
+
+import "testing"
+
+func TestValidateStatusTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{"unchanged", AssetStatusLocked, AssetStatusLocked, false},
+		{"available to sold", AssetStatusAvailable, AssetStatusSold, false},
+		{"owned to maintenance", AssetStatusOwned, AssetStatusUnderMaintenance, false},
+		{"sold back to owned", AssetStatusSold, AssetStatusOwned, false},
+		{"locked to available", AssetStatusLocked, AssetStatusAvailable, false},
+		{"sold to available is not allowed", AssetStatusSold, AssetStatusAvailable, true},
+		{"locked to sold is not allowed", AssetStatusLocked, AssetStatusSold, true},
+		{"unknown target", AssetStatusAvailable, "Destroyed", true},
+		{"pending transfer cannot be left", AssetStatusPendingTransfer, AssetStatusOwned, true},
+		{"pending transfer cannot be entered", AssetStatusOwned, AssetStatusPendingTransfer, true},
+		{"archived cannot be left", AssetStatusArchived, AssetStatusOwned, true},
+		{"archived cannot be entered", AssetStatusOwned, AssetStatusArchived, true},
+		{"legacy free-form status moves to any valid status", "In Stock", AssetStatusSold, false},
+		{"legacy free-form status still needs a valid target", "In Stock", "Gone", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStatusTransition(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateStatusTransition(%q, %q) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequireTransferable(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantErr bool
+	}{
+		{AssetStatusAvailable, false},
+		{AssetStatusOwned, false},
+		{AssetStatusSold, true},
+		{AssetStatusLocked, true},
+		{AssetStatusUnderMaintenance, true},
+		{AssetStatusPendingTransfer, true},
+		{AssetStatusArchived, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			err := requireTransferable(&Asset{ID: "asset1", Status: tt.status})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("requireTransferable(%q) error = %v, wantErr %v", tt.status, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Token ledger. A single fungible token, counted in whole units, is held in per-account balances.
+// Admins mint tokens and any active user can pay another. A transfer that carries a price
+// (see InitiateTransfer) settles delivery-versus-payment: the approval that executes it debits
+// the buyer and credits the seller in the same transaction that moves the asset, or fails and
+// moves neither.
+
+// Token movement kinds
+const (
+	MovementMint       = "MINT"
+	MovementTransfer   = "TRANSFER"
+	MovementSettlement = "SETTLEMENT" // Payment of a priced asset transfer
+)
+
+// TokenBalance is the token holding of one account
+type TokenBalance struct {
+	DocType   string `json:"docType"` // "token_balance"
+	Account   string `json:"account"`
+	Balance   int64  `json:"balance"`
+	UpdatedAt int64  `json:"updatedAt"`
+	Sequence  uint64 `json:"sequence"`
+}
+
+// TokenMovement is the payload of TokensMinted and TokensTransferred, and the payment
+// carried by a settled TransferExecuted event. Balances are the ones after the movement.
+type TokenMovement struct {
+	Kind        string `json:"kind"`
+	From        string `json:"from,omitempty"` // Empty for mints
+	To          string `json:"to"`
+	Amount      int64  `json:"amount"`
+	FromBalance int64  `json:"fromBalance"`
+	ToBalance   int64  `json:"toBalance"`
+	AssetID     string `json:"assetId,omitempty"` // Settlements only
+	Actor       string `json:"actor"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// readBalance returns the balance of an account; accounts that never held tokens have a zero balance
+func readBalance(ctx contractapi.TransactionContextInterface, account string) (*TokenBalance, error) {
+	key, err := balanceKey(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	balanceJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if balanceJSON == nil {
+		return &TokenBalance{DocType: "token_balance", Account: account}, nil
+	}
+
+	var balance TokenBalance
+	if err := json.Unmarshal(balanceJSON, &balance); err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// moveTokens debits from (unless minting) and credits to, failing on an insufficient balance
+func moveTokens(ctx contractapi.TransactionContextInterface, kind string, from string, to string, amount int64, assetID string, actorID string, now int64) (*TokenMovement, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("the amount must be positive, got %d", amount)
+	}
+	if from == to {
+		return nil, fmt.Errorf("cannot move tokens from %s to itself", from)
+	}
+	movement := &TokenMovement{Kind: kind, From: from, To: to, Amount: amount, AssetID: assetID, Actor: actorID, Timestamp: now}
+
+	if from != "" {
+		source, err := readBalance(ctx, from)
+		if err != nil {
+			return nil, err
+		}
+		if source.Balance < amount {
+			return nil, fmt.Errorf("insufficient balance: %s holds %d tokens, %d required", from, source.Balance, amount)
+		}
+		source.Balance -= amount
+		source.UpdatedAt = now
+		source.Sequence = source.Sequence + 1
+		if err := putBalance(ctx, source); err != nil {
+			return nil, err
+		}
+		movement.FromBalance = source.Balance
+	}
+
+	target, err := readBalance(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	if target.Balance > math.MaxInt64-amount {
+		return nil, fmt.Errorf("the balance of %s would overflow", to)
+	}
+	target.Balance += amount
+	target.UpdatedAt = now
+	target.Sequence = target.Sequence + 1
+	if err := putBalance(ctx, target); err != nil {
+		return nil, err
+	}
+	movement.ToBalance = target.Balance
+	return movement, nil
+}
+
+// emitTokenEvent sets the transaction event for a token movement
+func emitTokenEvent(ctx contractapi.TransactionContextInterface, name string, movement *TokenMovement) error {
+	eventJSON, err := json.Marshal(movement)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent(name, eventJSON)
+}
+
+// settlePayment pays the price of an executing transfer from the new owner to the current owner.
+// It returns nil for transfers without a price.
+func settlePayment(ctx contractapi.TransactionContextInterface, pending *PendingTransfer, actorID string, now int64) (*TokenMovement, error) {
+	if pending.Price == 0 {
+		return nil, nil
+	}
+	movement, err := moveTokens(ctx, MovementSettlement, pending.NewOwner, pending.CurrentOwner, pending.Price, pending.AssetID, actorID, now)
+	if err != nil {
+		return nil, fmt.Errorf("payment for asset %s failed: %v", pending.AssetID, err)
+	}
+	return movement, nil
+}
+
+// MintTokens creates new tokens in the account of a registered user
+func (s *SmartContract) MintTokens(ctx contractapi.TransactionContextInterface, account string, amount int64) (*TokenMovement, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	adminID, err := activeCallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.ReadUser(ctx, account); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	movement, err := moveTokens(ctx, MovementMint, "", account, amount, "", adminID, timestamp.Seconds)
+	if err != nil {
+		return nil, err
+	}
+	return movement, emitTokenEvent(ctx, "TokensMinted", movement)
+}
+
+// TransferTokens pays tokens from the caller's account to another registered, unlocked user
+func (s *SmartContract) TransferTokens(ctx contractapi.TransactionContextInterface, to string, amount int64) (*TokenMovement, error) {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.ReadUser(ctx, to); err != nil {
+		return nil, err
+	}
+	if err := requireUnlocked(ctx, to); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	movement, err := moveTokens(ctx, MovementTransfer, callerID, to, amount, "", callerID, timestamp.Seconds)
+	if err != nil {
+		return nil, err
+	}
+	return movement, emitTokenEvent(ctx, "TokensTransferred", movement)
+}
+
+// GetTokenBalance returns the balance of an account. Users read their own balance; admins and auditors any.
+func (s *SmartContract) GetTokenBalance(ctx contractapi.TransactionContextInterface, account string) (*TokenBalance, error) {
+	callerID, err := getCallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if account != callerID {
+		if err := requireAuditor(ctx); err != nil {
+			return nil, fmt.Errorf("access denied: only %s, an auditor or an admin can read this balance", account)
+		}
+	}
+	return readBalance(ctx, account)
+}
@@ -0,0 +1,309 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Pending transfer statuses
+const (
+	TransferStatusPending   = "PENDING"
+	TransferStatusExecuted  = "EXECUTED"
+	TransferStatusRejected  = "REJECTED"
+	TransferStatusExpired   = "EXPIRED"
+	TransferStatusInvalid   = "INVALID"
+	TransferStatusCancelled = "CANCELLED"
+)
+
+// TransferEvent is the payload of the Transfer* events.
+// It carries the asset, and the assets bundled with it, whenever the transfer changed them
+// (lock, release or new owner) and the payment of a priced transfer, because Fabric
+// delivers only one event per transaction.
+type TransferEvent struct {
+	*PendingTransfer
+	Asset    *Asset         `json:"asset,omitempty"`
+	Children []*Asset       `json:"children,omitempty"`
+	Payment  *TokenMovement `json:"payment,omitempty"`
+}
+
+// emitTransferEvent sets the transaction event for a pending transfer and, optionally, the assets it changed
+func emitTransferEvent(ctx contractapi.TransactionContextInterface, name string, pending *PendingTransfer, asset *Asset, children ...*Asset) error {
+	return setTransferEvent(ctx, name, TransferEvent{PendingTransfer: pending, Asset: asset, Children: children})
+}
+
+// setTransferEvent sets a fully built transfer event as the transaction event
+func setTransferEvent(ctx contractapi.TransactionContextInterface, name string, event TransferEvent) error {
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transfer event: %v", err)
+	}
+	return ctx.GetStub().SetEvent(name, eventJSON)
+}
+
+// requireNoTransferLock fails if the asset is locked by a pending multi-sig transfer
+func requireNoTransferLock(asset *Asset) error {
+	if asset.Status == AssetStatusPendingTransfer {
+		return fmt.Errorf("asset %s is locked by a pending transfer", asset.ID)
+	}
+	return nil
+}
+
+// lockAssetForTransfer moves the asset into the pending-transfer lock, remembering its status
+func lockAssetForTransfer(ctx contractapi.TransactionContextInterface, asset *Asset, actorID string, now int64) error {
+	asset.StatusBeforeTransfer = asset.Status
+	asset.Status = AssetStatusPendingTransfer
+	asset.UpdatedAt = now
+	asset.LastModifiedBy = actorID
+	asset.Sequence = asset.Sequence + 1
+
+	_, err := putAsset(ctx, asset)
+	return err
+}
+
+// restoreStatusAfterTransfer puts back the status the asset had before it was locked
+func restoreStatusAfterTransfer(asset *Asset) {
+	asset.Status = asset.StatusBeforeTransfer
+	if asset.Status == "" {
+		asset.Status = AssetStatusOwned
+	}
+	asset.StatusBeforeTransfer = ""
+}
+
+// releaseTransferLock lifts the pending-transfer lock on an asset without changing its owner.
+// It returns nil when the asset no longer exists, is not locked or is frozen; a frozen asset
+// keeps its lock until UnfreezeAsset lifts it.
+func (s *SmartContract) releaseTransferLock(ctx contractapi.TransactionContextInterface, assetID string, actorID string, now int64) (*Asset, error) {
+	exists, err := s.AssetExists(ctx, assetID)
+	if err != nil || !exists {
+		return nil, err
+	}
+	asset, err := s.ReadAsset(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if asset.Status != AssetStatusPendingTransfer || isFrozen(asset) {
+		return nil, nil
+	}
+
+	restoreStatusAfterTransfer(asset)
+	asset.UpdatedAt = now
+	asset.LastModifiedBy = actorID
+	asset.Sequence = asset.Sequence + 1
+
+	if _, err := putAsset(ctx, asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// moveBundledAssets hands the assets locked with an executing transfer to the new owner and lifts their lock
+func (s *SmartContract) moveBundledAssets(ctx contractapi.TransactionContextInterface, pending *PendingTransfer, actorID string, now int64) ([]*Asset, error) {
+	children := []*Asset{}
+	for _, childID := range pending.BundledAssets {
+		child, err := s.ReadAsset(ctx, childID)
+		if err != nil {
+			return nil, err
+		}
+		if err := requireNotFrozen(child); err != nil {
+			return nil, err
+		}
+		if err := requireAttestations(ctx, child, now); err != nil {
+			return nil, err
+		}
+		assignOwner(child, pending.NewOwner)
+		if child.Status == AssetStatusPendingTransfer {
+			restoreStatusAfterTransfer(child)
+		}
+		child.UpdatedAt = now
+		child.LastModifiedBy = actorID
+		child.Sequence = child.Sequence + 1
+
+		if _, err := putAsset(ctx, child); err != nil {
+			return nil, fmt.Errorf("failed to update ownership of attached asset %s: %v", childID, err)
+		}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// CancelTransfer lets the initiator (current owner) withdraw a pending transfer
+func (s *SmartContract) CancelTransfer(ctx contractapi.TransactionContextInterface, assetID string, reason string) error {
+	callerID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending, err := s.GetPendingTransfer(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if pending.Status != TransferStatusPending {
+		return fmt.Errorf("transfer is no longer pending. Status: %s", pending.Status)
+	}
+	if callerID != pending.CurrentOwner {
+		return fmt.Errorf("only the initiator can cancel a transfer. Initiator: %s, Caller: %s", pending.CurrentOwner, callerID)
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	pending.CancelledBy = callerID
+	pending.CancellationReason = reason
+	_, err = s.closeTransfer(ctx, pending, TransferStatusCancelled, "TransferCancelled", callerID, timestamp.Seconds)
+	return err
+}
+
+// AdminCancelTransfer lets an admin clear a stuck pending transfer. The admin and the reason are recorded on the transfer.
+func (s *SmartContract) AdminCancelTransfer(ctx contractapi.TransactionContextInterface, assetID string, reason string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	adminID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("a reason is required to cancel a transfer as admin")
+	}
+
+	pending, err := s.GetPendingTransfer(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if pending.Status != TransferStatusPending {
+		return fmt.Errorf("transfer is no longer pending. Status: %s", pending.Status)
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	pending.CancelledBy = adminID
+	pending.CancellationReason = reason
+	pending.CancelledByAdmin = true
+	_, err = s.closeTransfer(ctx, pending, TransferStatusCancelled, "TransferCancelled", adminID, timestamp.Seconds)
+	return err
+}
+
+// ForceTransferEvent is the payload of AssetForceTransferred.
+// CancelledTransfer is the pending transfer the forced transfer superseded, if any.
+type ForceTransferEvent struct {
+	Asset             *Asset           `json:"asset"`
+	PreviousOwner     string           `json:"previousOwner"`
+	NewOwner          string           `json:"newOwner"`
+	Reason            string           `json:"reason"`
+	DocumentHash      string           `json:"documentHash"` // SHA-256 of the document authorising the transfer
+	Actor             string           `json:"actor"`
+	Timestamp         int64            `json:"timestamp"`
+	CancelledTransfer *PendingTransfer `json:"cancelledTransfer,omitempty"`
+}
+
+// AdminForceTransfer moves an asset to a new owner without the owners' approval (admin only),
+// e.g. to execute a court order. The reason and the hash of the authorising document are recorded
+// in the event. A pending transfer on the asset is cancelled first.
+func (s *SmartContract) AdminForceTransfer(ctx contractapi.TransactionContextInterface, id string, newOwner string, reason string, documentHash string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	adminID, err := activeCallerID(ctx)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("a reason is required to force a transfer")
+	}
+	if !documentHashPattern.MatchString(documentHash) {
+		return fmt.Errorf("documentHash must be a hex-encoded SHA-256 digest")
+	}
+	if _, err := s.ReadUser(ctx, newOwner); err != nil {
+		return err
+	}
+	if err := requireUnlocked(ctx, newOwner); err != nil {
+		return err
+	}
+
+	asset, err := s.ReadAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+	if asset.Owner == newOwner {
+		return fmt.Errorf("asset %s is already owned by %s", id, newOwner)
+	}
+	if err := requireNotArchived(asset); err != nil {
+		return err
+	}
+	if err := requireNotFrozen(asset); err != nil {
+		return err
+	}
+	if err := requireSoleHolder(asset, asset.Owner); err != nil {
+		return err
+	}
+	if err := requireNoBundle(asset); err != nil {
+		return err
+	}
+	if err := requireNotOnLoan(asset); err != nil {
+		return err
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return err
+	}
+	now := timestamp.Seconds
+
+	// A pending transfer would otherwise execute against the new owner's asset
+	pending, err := s.heldTransfer(ctx, id, TransferStatusPending)
+	if err != nil {
+		return err
+	}
+	if pending != nil {
+		pending.CancelledBy = adminID
+		pending.CancellationReason = "superseded by forced transfer: " + reason
+		pending.CancelledByAdmin = true
+		released, _, err := s.endTransfer(ctx, pending, TransferStatusCancelled, adminID, now)
+		if err != nil {
+			return err
+		}
+		if released != nil {
+			asset = released
+		}
+	}
+
+	if err := requireTransferable(asset); err != nil {
+		return err
+	}
+	if err := requireAttestations(ctx, asset, now); err != nil {
+		return err
+	}
+
+	previousOwner := asset.Owner
+	assignOwner(asset, newOwner)
+	asset.UpdatedAt = now
+	asset.LastModifiedBy = adminID
+	asset.Sequence = asset.Sequence + 1
+
+	if _, err := putAsset(ctx, asset); err != nil {
+		return err
+	}
+
+	eventJSON, err := json.Marshal(ForceTransferEvent{
+		Asset:             asset,
+		PreviousOwner:     previousOwner,
+		NewOwner:          newOwner,
+		Reason:            reason,
+		DocumentHash:      strings.ToLower(documentHash),
+		Actor:             adminID,
+		Timestamp:         now,
+		CancelledTransfer: pending,
+	})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent("AssetForceTransferred", eventJSON)
+}
@@ -0,0 +1,77 @@
+package chaincode
+
+import "testing"
+
+const testDocumentHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func TestAdminForceTransfer(t *testing.T) {
+	tests := []struct {
+		name         string
+		callerID     string
+		callerRole   string
+		newOwner     string
+		reason       string
+		documentHash string
+		wantErr      bool
+	}{
+		{"admin with court order", "admin", RoleAdmin, "bob", "court order 42", testDocumentHash, false},
+		{"owner", "alice", RoleUser, "bob", "court order 42", testDocumentHash, true},
+		{"auditor", "auditor", RoleAuditor, "bob", "court order 42", testDocumentHash, true},
+		{"unknown recipient", "admin", RoleAdmin, "leonardo", "court order 42", testDocumentHash, true},
+		{"locked recipient", "admin", RoleAdmin, "mallory", "court order 42", testDocumentHash, true},
+		{"current owner", "admin", RoleAdmin, "alice", "court order 42", testDocumentHash, true},
+		{"missing reason", "admin", RoleAdmin, "bob", " ", testDocumentHash, true},
+		{"invalid document hash", "admin", RoleAdmin, "bob", "court order 42", "not-a-hash", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := newTestLedger(t)
+			ledger.seedUsers(map[string]string{"alice": RoleUser, "bob": RoleUser, "mallory": RoleUser})
+			ledger.seedAsset(&Asset{ID: "asset1", Owner: "alice", Status: AssetStatusOwned, Sequence: 1})
+			contract := &SmartContract{}
+			if err := contract.SetUserStatus(ledger.as("admin", RoleAdmin), "mallory", UserStatusLocked); err != nil {
+				t.Fatalf("SetUserStatus: %v", err)
+			}
+
+			err := contract.AdminForceTransfer(ledger.as(tt.callerID, tt.callerRole), "asset1", tt.newOwner, tt.reason, tt.documentHash)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AdminForceTransfer() as %s error = %v, wantErr %v", tt.callerID, err, tt.wantErr)
+			}
+			wantOwner := "alice"
+			if !tt.wantErr {
+				wantOwner = tt.newOwner
+			}
+			if got := ledger.asset("asset1"); got.Owner != wantOwner {
+				t.Fatalf("owner = %q, want %q", got.Owner, wantOwner)
+			}
+		})
+	}
+}
+
+func TestAdminForceTransferCancelsPendingTransfer(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.seedUsers(map[string]string{"alice": RoleUser, "bob": RoleUser, "carol": RoleUser})
+	ledger.seedAsset(&Asset{ID: "asset1", Name: "Car", Type: "Vehicle", Owner: "alice", Status: AssetStatusOwned, Sequence: 1})
+	contract := &SmartContract{}
+
+	if _, err := contract.InitiateTransfer(ledger.as("alice", RoleUser), "asset1", "bob", 0, 0); err != nil {
+		t.Fatalf("InitiateTransfer: %v", err)
+	}
+	if err := contract.AdminForceTransfer(ledger.as("admin", RoleAdmin), "asset1", "carol", "court order 42", testDocumentHash); err != nil {
+		t.Fatalf("AdminForceTransfer: %v", err)
+	}
+
+	pending, err := contract.GetPendingTransfer(ledger.as("alice", RoleUser), "asset1")
+	if err != nil {
+		t.Fatalf("GetPendingTransfer: %v", err)
+	}
+	if pending.Status != TransferStatusCancelled || !pending.CancelledByAdmin {
+		t.Fatalf("transfer status = %q (by admin %v), want %q by admin", pending.Status, pending.CancelledByAdmin, TransferStatusCancelled)
+	}
+	if got := ledger.asset("asset1"); got.Owner != "carol" || got.Status != AssetStatusOwned {
+		t.Fatalf("owner = %q, status = %q, want carol and %q", got.Owner, got.Status, AssetStatusOwned)
+	}
+	if _, err := contract.ApproveTransfer(ledger.as("bob", RoleUser), "asset1"); err == nil {
+		t.Fatal("the superseded transfer was approved")
+	}
+}
@@ -3,22 +3,32 @@
 # Script to test the basic chaincode functionality using the CLI container
 # Usage: ./test_network.sh
 
+# Identities enrolled by scripts/fresh_start.sh (role attribute in the certificate), as seen inside the cli container
+USERS_DIR=/opt/gopath/src/github.com/hyperledger/fabric/peer/organizations/peerOrganizations/org1.example.com/users
+AS_BRAD="CORE_PEER_MSPCONFIGPATH=${USERS_DIR}/Brad@org1.example.com/msp"
+AS_ADMIN="CORE_PEER_MSPCONFIGPATH=${USERS_DIR}/admin@org1.example.com/msp"
+
 echo "--- 1. Query All Assets (Initial State) ---"
 docker exec cli peer chaincode query -C mychannel -n basic -c '{"Args":["GetAllAssets"]}'
 echo ""
 
-echo "--- 2. Create a New Asset (iPhone 16) - AssetID: asset99 ---"
-docker exec cli peer chaincode invoke -o orderer1.example.com:7050 --ordererTLSHostnameOverride orderer1.example.com --tls --cafile /opt/gopath/src/github.com/hyperledger/fabric/peer/organizations/ordererOrganizations/example.com/orderers/orderer1.example.com/msp/tlscacerts/tlsca.example.com-cert.pem -C mychannel -n basic -c '{"Args":["CreateAsset","asset99","iPhone 16","Electronics","Alice","Pre-order","http://apple.com/iphone16","dummy_hash_for_test"]}'
+echo "--- 2. Create a New Asset as Brad (iPhone 16) - AssetID: asset99 ---"
+docker exec -e "$AS_BRAD" cli peer chaincode invoke -o orderer1.example.com:7050 --ordererTLSHostnameOverride orderer1.example.com --tls --cafile /opt/gopath/src/github.com/hyperledger/fabric/peer/organizations/ordererOrganizations/example.com/orderers/orderer1.example.com/msp/tlscacerts/tlsca.example.com-cert.pem -C mychannel -n basic -c '{"Args":["CreateAsset","asset99","iPhone 16","Electronics","Brad","Available","http://apple.com/iphone16","dummy_hash_for_test",""]}'
 sleep 3
 echo ""
 
-echo "--- 3. Transfer Asset (asset3 from Jin Soo to Leonardo) ---"
-docker exec cli peer chaincode invoke -o orderer1.example.com:7050 --ordererTLSHostnameOverride orderer1.example.com --tls --cafile /opt/gopath/src/github.com/hyperledger/fabric/peer/organizations/ordererOrganizations/example.com/orderers/orderer1.example.com/msp/tlscacerts/tlsca.example.com-cert.pem -C mychannel -n basic -c '{"Args":["TransferAsset","asset3","Leonardo"]}'
+echo "--- 3. Force Transfer Asset as admin (asset3 from JinSoo to Brad) ---"
+docker exec -e "$AS_ADMIN" cli peer chaincode invoke -o orderer1.example.com:7050 --ordererTLSHostnameOverride orderer1.example.com --tls --cafile /opt/gopath/src/github.com/hyperledger/fabric/peer/organizations/ordererOrganizations/example.com/orderers/orderer1.example.com/msp/tlscacerts/tlsca.example.com-cert.pem -C mychannel -n basic -c '{"Args":["AdminForceTransfer","asset3","Brad","Test network smoke test","e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"]}'
 sleep 3
 echo ""
 
 echo "--- 4. Verify Transfer (Read asset3) ---"
-docker exec cli peer chaincode query -C mychannel -n basic -c '{"Args":["ReadAsset","asset3"]}'
+ASSET3=$(docker exec cli peer chaincode query -C mychannel -n basic -c '{"Args":["ReadAsset","asset3"]}')
+echo "$ASSET3"
+if ! echo "$ASSET3" | grep -q '"owner":"Brad"'; then
+    echo "❌ asset3 was not force-transferred to Brad"
+    exit 1
+fi
 echo ""
 
 echo "--- 5. Verify Creation (Read asset99) ---"
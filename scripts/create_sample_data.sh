@@ -66,7 +66,7 @@ create_asset "asset201" "Tesla Model S Plaid" "Vehicle" "Brad" "Available" "http
 create_asset "asset202" "Porsche 911 GT3" "Vehicle" "Brad" "Available" "https://example.com/meta/porsche.json"
 
 # 3. Art Assets (JinSoo)
-create_asset "asset301" "Mona Lisa Replica" "Art" "JinSoo" "Private" "https://example.com/meta/art1.json"
+create_asset "asset301" "Mona Lisa Replica" "Art" "JinSoo" "Owned" "https://example.com/meta/art1.json"
 create_asset "asset302" "Ancient Vase" "Art" "JinSoo" "Available" "https://example.com/meta/vase.json"
 
 # 4. Tech Assets (Max)
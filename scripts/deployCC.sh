@@ -18,6 +18,18 @@ DELAY=${10:-"3"}
 MAX_RETRY=${11:-"5"}
 VERBOSE=${12:-"false"}
 
+# Private data collections: default to the config shipped with the chaincode source.
+# The cli container only mounts channel-artifacts, so the file is staged there.
+if [ "$CC_COLL_CONFIG" == "NA" ] && [ -f "${CC_SRC_PATH}/collections_config.json" ]; then
+  CC_COLL_CONFIG="${CC_SRC_PATH}/collections_config.json"
+fi
+COLL_CONFIG_FLAG=""
+if [ "$CC_COLL_CONFIG" != "NA" ]; then
+  mkdir -p channel-artifacts
+  cp $CC_COLL_CONFIG channel-artifacts/collections_config.json
+  COLL_CONFIG_FLAG="--collections-config ./channel-artifacts/collections_config.json"
+fi
+
 println() {
   echo "$1"
 }
@@ -114,7 +126,7 @@ approveForMyOrg() {
               -e CORE_PEER_TLS_ROOTCERT_FILE=$CORE_PEER_TLS_ROOTCERT_FILE \
               -e CORE_PEER_MSPCONFIGPATH=$CORE_PEER_MSPCONFIGPATH \
               -e CORE_PEER_ADDRESS=$CORE_PEER_ADDRESS \
-              cli peer lifecycle chaincode approveformyorg -o orderer1.example.com:7050 --ordererTLSHostnameOverride orderer1.example.com --tls --cafile $ORDERER_CA --channelID $CHANNEL_NAME --name $CC_NAME --version $CC_VERSION --package-id $PACKAGE_ID --sequence $CC_SEQUENCE --init-required >&log.txt
+              cli peer lifecycle chaincode approveformyorg -o orderer1.example.com:7050 --ordererTLSHostnameOverride orderer1.example.com --tls --cafile $ORDERER_CA --channelID $CHANNEL_NAME --name $CC_NAME --version $CC_VERSION --package-id $PACKAGE_ID --sequence $CC_SEQUENCE --init-required $COLL_CONFIG_FLAG >&log.txt
   res=$?
   { set +x; } 2>/dev/null
   cat log.txt
@@ -136,7 +148,7 @@ commitChaincodeDefinition() {
               -e CORE_PEER_TLS_ROOTCERT_FILE=$CORE_PEER_TLS_ROOTCERT_FILE \
               -e CORE_PEER_MSPCONFIGPATH=$CORE_PEER_MSPCONFIGPATH \
               -e CORE_PEER_ADDRESS=$CORE_PEER_ADDRESS \
-              cli peer lifecycle chaincode commit -o orderer1.example.com:7050 --ordererTLSHostnameOverride orderer1.example.com --tls --cafile $ORDERER_CA --channelID $CHANNEL_NAME --name $CC_NAME --version $CC_VERSION --sequence $CC_SEQUENCE --init-required >&log.txt
+              cli peer lifecycle chaincode commit -o orderer1.example.com:7050 --ordererTLSHostnameOverride orderer1.example.com --tls --cafile $ORDERER_CA --channelID $CHANNEL_NAME --name $CC_NAME --version $CC_VERSION --sequence $CC_SEQUENCE --init-required $COLL_CONFIG_FLAG >&log.txt
   res=$?
   { set +x; } 2>/dev/null
   cat log.txt
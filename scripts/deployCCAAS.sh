@@ -11,6 +11,13 @@ CC_VERSION=${4:-"1.0"}
 CC_SEQUENCE=${5:-"1"}
 docker_network_name="fabric_network"
 
+# Private data collections shipped with the chaincode source (assetPrivateDetailsCollection)
+CC_COLL_CONFIG="${CC_SRC_PATH}/collections_config.json"
+COLL_CONFIG_FLAG=""
+if [ -f "$CC_COLL_CONFIG" ]; then
+  COLL_CONFIG_FLAG="--collections-config ./channel-artifacts/collections_config.json"
+fi
+
 # Setup CCAAS package
 preparePackage() {
   echo "Preparing CCAAS package..."
@@ -36,9 +43,17 @@ EOF
 }
 EOF
 
+  # The cli container only mounts channel-artifacts, so the collection config is staged there
+  if [ -n "$COLL_CONFIG_FLAG" ]; then
+    cp $CC_COLL_CONFIG channel-artifacts/collections_config.json
+  fi
+
+  # CouchDB indexes: the peer deploys META-INF/statedb/couchdb/indexes from code.tar.gz
+  cp -r ${CC_SRC_PATH}/META-INF chaincode/asset-transfer/ccaas/
+
   # Tar the package
   pushd chaincode/asset-transfer/ccaas
-  tar -czf code.tar.gz connection.json
+  tar -czf code.tar.gz connection.json META-INF
   tar -czf ${CC_NAME}.tar.gz metadata.json code.tar.gz
   mv ${CC_NAME}.tar.gz ../../../channel-artifacts/
   popd
@@ -107,7 +122,7 @@ approveForMyOrg() {
               -e CORE_PEER_TLS_ROOTCERT_FILE=$CORE_PEER_TLS_ROOTCERT_FILE \
               -e CORE_PEER_MSPCONFIGPATH=$CORE_PEER_MSPCONFIGPATH \
               -e CORE_PEER_ADDRESS=$CORE_PEER_ADDRESS \
-              cli peer lifecycle chaincode approveformyorg -o orderer1.example.com:7050 --ordererTLSHostnameOverride orderer1.example.com --tls --cafile $ORDERER_CA --channelID $CHANNEL_NAME --name $CC_NAME --version $CC_VERSION --package-id $PACKAGE_ID --sequence $CC_SEQUENCE --init-required >&log.txt
+              cli peer lifecycle chaincode approveformyorg -o orderer1.example.com:7050 --ordererTLSHostnameOverride orderer1.example.com --tls --cafile $ORDERER_CA --channelID $CHANNEL_NAME --name $CC_NAME --version $CC_VERSION --package-id $PACKAGE_ID --sequence $CC_SEQUENCE --init-required $COLL_CONFIG_FLAG >&log.txt
   res=$?
   { set +x; } 2>/dev/null
   cat log.txt
@@ -128,7 +143,7 @@ commitChaincodeDefinition() {
               -e CORE_PEER_TLS_ROOTCERT_FILE=$CORE_PEER_TLS_ROOTCERT_FILE \
               -e CORE_PEER_MSPCONFIGPATH=$CORE_PEER_MSPCONFIGPATH \
               -e CORE_PEER_ADDRESS=$CORE_PEER_ADDRESS \
-              cli peer lifecycle chaincode commit -o orderer1.example.com:7050 --ordererTLSHostnameOverride orderer1.example.com --tls --cafile $ORDERER_CA --channelID $CHANNEL_NAME --name $CC_NAME --version $CC_VERSION --sequence $CC_SEQUENCE --init-required >&log.txt
+              cli peer lifecycle chaincode commit -o orderer1.example.com:7050 --ordererTLSHostnameOverride orderer1.example.com --tls --cafile $ORDERER_CA --channelID $CHANNEL_NAME --name $CC_NAME --version $CC_VERSION --sequence $CC_SEQUENCE --init-required $COLL_CONFIG_FLAG >&log.txt
   res=$?
   { set +x; } 2>/dev/null
   cat log.txt
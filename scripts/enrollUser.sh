@@ -1,15 +1,16 @@
 #!/bin/bash
 # scripts/enrollUser.sh
-# Usage: ./enrollUser.sh <username> <password>
+# Usage: ./enrollUser.sh <username> <password> [role]
 
 USERNAME=$1
 PASSWORD=$2
+ROLE=${3:-User} # Admin, User or Auditor - issued as the 'role' attribute in the enrollment certificate
 ORG_DOMAIN="org1.example.com"
 CA_PORT=7054
 CA_NAME="ca-org1"
 
 if [ -z "$USERNAME" ] || [ -z "$PASSWORD" ]; then
-    echo "Usage: ./enrollUser.sh <username> <password>"
+    echo "Usage: ./enrollUser.sh <username> <password> [role]"
     exit 1
 fi
 
@@ -22,7 +23,7 @@ USER_DIR="${ORG_DIR}/users/${USERNAME}@${ORG_DOMAIN}"
 CA_TLS_CERT="${NETWORK_DIR}/organizations/fabric-ca/org1/ca-cert.pem"
 
 echo "Using CA at localhost:${CA_PORT}"
-echo "Registering and Enrolling user: ${USERNAME}"
+echo "Registering and Enrolling user: ${USERNAME} (role: ${ROLE})"
 
 # 1. Register (must be done by admin)
 # We assume 'admin' identity is already enrolled at organizations/peerOrganizations/org1.example.com/users/Admin@org1.example.com
@@ -39,7 +40,7 @@ export FABRIC_CA_CLIENT_HOME=${ORG_DIR}
 
 # Check if user already registered (idempotent)
 echo "--- Registering ${USERNAME} ---"
-fabric-ca-client register --caname ${CA_NAME} --id.name ${USERNAME} --id.secret ${PASSWORD} --id.type client --tls.certfiles ${CA_TLS_CERT}
+fabric-ca-client register --caname ${CA_NAME} --id.name ${USERNAME} --id.secret ${PASSWORD} --id.type client --id.attrs "role=${ROLE}:ecert" --tls.certfiles ${CA_TLS_CERT}
 # Ignore "Identity ... already exists" error
 
 # 2. Enroll
@@ -47,8 +47,10 @@ chmod +x scripts/enrollUser.sh
 ./scripts/enrollUser.sh Adriana password
 
 ./scripts/enrollUser.sh Michel password
-./scripts/enrollUser.sh admin adminpw
-./scripts/enrollUser.sh auditor auditor123
+./scripts/enrollUser.sh admin adminpw Admin
+./scripts/enrollUser.sh auditor auditor123 Auditor
+# Backend service identity used by scheduled jobs (transfer expiry sweeper)
+./scripts/enrollUser.sh system systempw System
 
 # 7. Launch Application (DB First)
 echo "🚀 [Step 7/7] Launching Application..."